@@ -3,38 +3,23 @@ package rails
 import (
 	"context"
 	"crypto/sha3"
-	"encoding/base64"
 	"hash"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/blake2b"
 
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/job"
 	"github.com/snowmerak/keycl/lib/store"
 	"github.com/snowmerak/keycl/lib/store/queries"
+	"github.com/snowmerak/keycl/lib/topology"
+	"github.com/snowmerak/keycl/lib/util/password"
 	"github.com/snowmerak/keycl/model/gen/rails"
 )
 
-func HashPassword(step1, step2 hash.Hash, salt string, password string) string {
-	saltBytes := []byte(salt)
-	value := []byte(password)
-	step := 0
-	for step < 12 {
-		step1.Reset()
-		step1.Write(saltBytes)
-		step1.Write(value)
-		value = step1.Sum(nil)
-	}
-	step = 0
-	for step < 12 {
-		step2.Reset()
-		step2.Write(saltBytes)
-		step2.Write(value)
-		value = step2.Sum(nil)
-	}
-	return base64.URLEncoding.EncodeToString(saltBytes)
-}
-
-func RegisterDefaultHandlers(h *Handler, st *store.Store) error {
+// RegisterDefaultHandlers handles login and failover requests and, unless jobs is nil, pushes job progress to logged in sessions.
+func RegisterDefaultHandlers(h *Handler, st *store.Store, newOperator cli.OperatorFactory, jobs *job.Manager) error {
 	passwordHash1, passwordHash2 := func() hash.Hash {
 		return sha3.New512()
 	}, func() hash.Hash {
@@ -43,10 +28,11 @@ func RegisterDefaultHandlers(h *Handler, st *store.Store) error {
 	}
 
 	h.RegisterCallback(func(ctx context.Context, state *SessionState, request *rails.Message, send func(*rails.Message)) error {
-		if !state.lock.TryLock() {
+		if !state.operating.TryLock() {
 			send(CommonResponse(false, "Already operating by another request"))
 			return nil
 		}
+		defer state.operating.Unlock()
 
 		switch req := request.Request.(type) {
 		case *rails.Message_LoginRequest:
@@ -54,26 +40,52 @@ func RegisterDefaultHandlers(h *Handler, st *store.Store) error {
 				passwordHash1: passwordHash1,
 				passwordHash2: passwordHash2,
 				store:         st,
+				newOperator:   newOperator,
+				state:         state,
 				send:          send,
 			}
 			defaultLoginRequest(ctx, &rs, req.LoginRequest)
+		case *rails.Message_FailoverNode:
+			if !state.Validated() {
+				send(CommonResponse(false, "Login required"))
+				return nil
+			}
+
+			rs := RequestSession{
+				store:       st,
+				newOperator: newOperator,
+				send:        send,
+			}
+			defaultFailoverNode(ctx, &rs, req.FailoverNode)
 		}
 		return nil
 	})
 
+	if jobs != nil {
+		BroadcastJobProgress(h, jobs)
+	}
+
 	return nil
 }
 
+// BroadcastJobProgress sends the progress of every job to all logged in sessions.
+func BroadcastJobProgress(h *Handler, jobs *job.Manager) {
+	jobs.OnProgress(func(j *job.Job, progress cli.Progress) {
+		h.Broadcast(ProgressResponse(j.ID, progress))
+	})
+}
+
 type RequestSession struct {
 	passwordHash1 func() hash.Hash
 	passwordHash2 func() hash.Hash
 	store         *store.Store
+	newOperator   cli.OperatorFactory
+	state         *SessionState
 	send          func(*rails.Message)
 }
 
 func defaultLoginRequest(ctx context.Context, rs *RequestSession, request *rails.LoginRequest) {
 	email := request.GetEmail()
-	password := request.GetPassword()
 	salt, registeredHash := "", ""
 	if err := rs.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
 		result, err := q.GetUserPassword(ctx, email)
@@ -91,12 +103,60 @@ func defaultLoginRequest(ctx context.Context, rs *RequestSession, request *rails
 		return
 	}
 
-	hashed := HashPassword(rs.passwordHash1(), rs.passwordHash2(), salt, password)
+	hashed := password.HashPassword(rs.passwordHash1(), rs.passwordHash2(), salt, request.GetPassword())
 	if hashed != registeredHash {
-		log.Error().Str("email", email).Str("hashed", hashed).Msg("Invalid email password")
+		log.Error().Str("email", email).Msg("Invalid email password")
 		rs.send(CommonResponse(false, "Invalid email or password"))
 		return
 	}
 
-	return
+	rs.state.SetEmail(email)
+	rs.state.SetValidated(true)
+
+	rs.send(CommonResponse(true, "Logged in"))
+}
+
+func defaultFailoverNode(ctx context.Context, rs *RequestSession, request *rails.FailoverNode) {
+	password := ""
+	var seeds []queries.Node
+	if err := rs.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		cluster, err := q.GetCluster(ctx, request.GetCluster())
+		if err != nil {
+			return err
+		}
+
+		password = cluster.Password
+
+		seeds, err = q.GetClusterNodes(ctx, cluster.Name)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Str("cluster", request.GetCluster()).Msg("Failed to get cluster")
+		rs.send(CommonResponse(false, "Cluster not found"))
+		return
+	}
+
+	operator := rs.newOperator(request.GetCluster(), password)
+
+	// the password of the cluster is only sent to a node the cluster knows
+	node, err := topology.ResolveNode(ctx, operator, seeds, request.GetHost(), int(request.GetPort()))
+	if err != nil {
+		log.Error().Err(err).Str("cluster", request.GetCluster()).Str("host", request.GetHost()).Int32("port", request.GetPort()).Msg("Failed to resolve node")
+		rs.send(ErrorResponse("Node not found in cluster", err))
+		return
+	}
+
+	if err := operator.Failover(ctx, node.Host, node.Port, cli.FailoverOptions{
+		Mode:    cli.FailoverMode(request.GetMode()),
+		Timeout: time.Duration(request.GetTimeoutSeconds()) * time.Second,
+	}); err != nil {
+		log.Error().Err(err).Str("cluster", request.GetCluster()).Str("host", request.GetHost()).Int32("port", request.GetPort()).Msg("Failed to failover node")
+		rs.send(ErrorResponse("Failed to failover node", err))
+		return
+	}
+
+	rs.send(CommonResponse(true, "Failover completed"))
 }
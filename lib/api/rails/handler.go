@@ -20,6 +20,8 @@ type SessionState struct {
 	email      string
 
 	lock *sync.RWMutex
+	// operating is held while a request of the session runs, so a session runs one operation at a time
+	operating sync.Mutex
 }
 
 func (s *SessionState) SetRemoteAddr(remoteAddr string) {
@@ -60,8 +62,37 @@ func (s *SessionState) Email() string {
 
 type Callback func(ctx context.Context, state *SessionState, request *rails.Message, send func(message *rails.Message)) error
 
+// sessionQueueSize is how many messages may wait for a slow connection before broadcasts to it are dropped.
+const sessionQueueSize = 256
+
+// session serializes every write to its connection through one writer, frames written concurrently would interleave.
+type session struct {
+	state  *SessionState
+	writes chan []byte
+}
+
+func (s *session) write(ctx context.Context, conn net.Conn) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case data := <-s.writes:
+			if err := wsutil.WriteServerBinary(conn, data); err != nil {
+				log.Error().Err(err).Str("remoteAddr", s.state.RemoteAddr()).Msg("Failed to write message")
+			}
+		}
+	}
+}
+
+func (s *session) send(ctx context.Context, data []byte) {
+	select {
+	case s.writes <- data:
+	case <-ctx.Done():
+	}
+}
+
 type Handler struct {
-	sessions     map[string]net.Conn
+	sessions     map[string]*session
 	sessionsLock sync.RWMutex
 
 	callbacks     []Callback
@@ -69,7 +100,9 @@ type Handler struct {
 }
 
 func NewHandler() (*Handler, error) {
-	return &Handler{}, nil
+	return &Handler{
+		sessions: make(map[string]*session),
+	}, nil
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -85,8 +118,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	remoteAddr := r.RemoteAddr
 
+	ss := &SessionState{
+		remoteAddr: remoteAddr,
+		lock:       new(sync.RWMutex),
+	}
+	s := &session{
+		state:  ss,
+		writes: make(chan []byte, sessionQueueSize),
+	}
+	go s.write(ctx, conn)
+
 	h.sessionsLock.Lock()
-	h.sessions[remoteAddr] = conn
+	h.sessions[remoteAddr] = s
 	h.sessionsLock.Unlock()
 
 	context.AfterFunc(ctx, func() {
@@ -95,10 +138,6 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.sessionsLock.Unlock()
 	})
 
-	ss := &SessionState{
-		remoteAddr: remoteAddr,
-	}
-
 	for {
 		data, err := wsutil.ReadClientBinary(conn)
 		if err != nil {
@@ -115,27 +154,21 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.callbacksLock.RLock()
 		for _, callback := range h.callbacks {
 			go callback(ctx, ss, message, func(response *rails.Message) {
-				defer func() {
-					if err := recover(); err != nil {
-						log.Error().Interface("err", err).Msg("Failed to send response")
-					}
-				}()
-
 				data, err := proto.Marshal(response)
 				if err != nil {
 					log.Error().Err(err).Msg("Failed to marshal response")
 					return
 				}
 
-				if err := wsutil.WriteServerBinary(conn, data); err != nil {
-					log.Error().Err(err).Msg("Failed to write response")
-				}
+				s.send(ctx, data)
 			})
 		}
 		h.callbacksLock.RUnlock()
 	}
 }
 
+// Broadcast sends the message to every logged in session. A session too slow to keep up misses the message
+// instead of holding up the sender.
 func (h *Handler) Broadcast(message *rails.Message) {
 	data, err := proto.Marshal(message)
 	if err != nil {
@@ -146,12 +179,16 @@ func (h *Handler) Broadcast(message *rails.Message) {
 	h.sessionsLock.RLock()
 	defer h.sessionsLock.RUnlock()
 
-	for _, conn := range h.sessions {
-		go func() {
-			if err := wsutil.WriteServerBinary(conn, data); err != nil {
-				log.Error().Err(err).Msg("Failed to write message")
-			}
-		}()
+	for _, s := range h.sessions {
+		if !s.state.Validated() {
+			continue
+		}
+
+		select {
+		case s.writes <- data:
+		default:
+			log.Warn().Str("remoteAddr", s.state.RemoteAddr()).Msg("Dropped message to slow session")
+		}
 	}
 }
 
@@ -0,0 +1,72 @@
+package rails
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/gobwas/ws/wsutil"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/model/gen/rails"
+)
+
+func TestBroadcast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, _ := NewHandler()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	validated := &session{
+		state:  &SessionState{remoteAddr: "10.0.0.1:5000", validated: true, lock: new(sync.RWMutex)},
+		writes: make(chan []byte, sessionQueueSize),
+	}
+	go validated.write(ctx, server)
+	h.sessions["10.0.0.1:5000"] = validated
+
+	// without a writer everything sent to the session stays in its queue
+	anonymous := &session{
+		state:  &SessionState{remoteAddr: "10.0.0.2:5000", lock: new(sync.RWMutex)},
+		writes: make(chan []byte, sessionQueueSize),
+	}
+	h.sessions["10.0.0.2:5000"] = anonymous
+
+	const events = 100
+	var wg sync.WaitGroup
+	for i := range events {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.Broadcast(ProgressResponse(1, cli.Progress{Operation: "migrate", Slot: i}))
+		}()
+	}
+
+	seen := make(map[int32]bool)
+	for range events {
+		data, err := wsutil.ReadServerBinary(client)
+		if err != nil {
+			t.Fatalf("ReadServerBinary() error = %v", err)
+		}
+
+		message := new(rails.Message)
+		if err := proto.Unmarshal(data, message); err != nil {
+			t.Fatalf("frame %d is corrupted: %v", len(seen), err)
+		}
+		seen[message.GetProgressEvent().GetSlot()] = true
+	}
+	wg.Wait()
+
+	if len(seen) != events {
+		t.Errorf("received %d distinct events, want %d", len(seen), events)
+	}
+
+	if len(anonymous.writes) != 0 {
+		t.Errorf("%d messages queued for a session that is not logged in", len(anonymous.writes))
+	}
+}
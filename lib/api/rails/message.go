@@ -1,6 +1,9 @@
 package rails
 
-import "github.com/snowmerak/keycl/model/gen/rails"
+import (
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/model/gen/rails"
+)
 
 func CommonResponse(success bool, message string) *rails.Message {
 	return &rails.Message{
@@ -13,6 +16,14 @@ func CommonResponse(success bool, message string) *rails.Message {
 	}
 }
 
+// ErrorResponse reports a failed operation, a known failure of the nodes is named instead of the raw command output.
+func ErrorResponse(message string, err error) *rails.Message {
+	if kind := cli.Classify(err); kind != nil {
+		return CommonResponse(false, message+": "+kind.Error())
+	}
+	return CommonResponse(false, message+": "+err.Error())
+}
+
 func ValueResponse(success bool, message string, value []byte) *rails.Message {
 	return &rails.Message{
 		Response: &rails.Message_ValueResponse{
@@ -24,3 +35,21 @@ func ValueResponse(success bool, message string, value []byte) *rails.Message {
 		},
 	}
 }
+
+func ProgressResponse(jobID int32, progress cli.Progress) *rails.Message {
+	return &rails.Message{
+		Response: &rails.Message_ProgressEvent{
+			ProgressEvent: &rails.ProgressEvent{
+				JobId:     jobID,
+				Operation: progress.Operation,
+				Slot:      int32(progress.Slot),
+				Source:    progress.Source,
+				Target:    progress.Target,
+				Keys:      int32(progress.Keys),
+				Done:      int32(progress.Done),
+				Total:     int32(progress.Total),
+				Message:   progress.Message,
+			},
+		},
+	}
+}
@@ -4,17 +4,23 @@ import (
 	"context"
 	"crypto/sha3"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hash"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/blake2b"
 
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/job"
 	"github.com/snowmerak/keycl/lib/store"
 	"github.com/snowmerak/keycl/lib/store/queries"
+	"github.com/snowmerak/keycl/lib/topology"
 	"github.com/snowmerak/keycl/lib/util/password"
 )
 
@@ -32,17 +38,94 @@ func defaultHash2() hash.Hash {
 }
 
 type API struct {
-	store   *store.Store
-	cliName string
+	store       *store.Store
+	newOperator cli.OperatorFactory
+	jobs        *job.Manager
+	snapshots   *topology.Recorder
+	syncer      *topology.Syncer
 }
 
-func New(store *store.Store, cliName string) *API {
+func New(store *store.Store, newOperator cli.OperatorFactory, jobs *job.Manager, snapshots *topology.Recorder, syncer *topology.Syncer) *API {
 	return &API{
-		store:   store,
-		cliName: cliName,
+		store:       store,
+		newOperator: newOperator,
+		jobs:        jobs,
+		snapshots:   snapshots,
+		syncer:      syncer,
 	}
 }
 
+// clusterOperator returns an operator for the cluster and its registered nodes, which serve as seeds for cluster commands.
+func (a *API) clusterOperator(ctx context.Context, q *queries.Queries, clusterName string) (cli.ClusterOperator, []queries.Node, error) {
+	cluster, err := q.GetCluster(ctx, clusterName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("q.GetCluster: %w", err)
+	}
+
+	nodes, err := q.GetClusterNodes(ctx, clusterName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("q.GetClusterNodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return nil, nil, fmt.Errorf("cluster %s has no registered nodes", clusterName)
+	}
+
+	return a.newOperator(cluster.Name, cluster.Password), nodes, nil
+}
+
+// trySeeds runs fn against the registered nodes in order until one of them succeeds. Only a node that cannot be
+// reached is passed over, any other error is returned right away.
+func trySeeds(nodes []queries.Node, fn func(host string, port int) error) error {
+	errs := make([]error, 0, len(nodes))
+	for _, node := range nodes {
+		err := fn(node.Host, int(node.Port))
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s:%d: %w", node.Host, node.Port, err))
+		if !cli.IsUnreachable(err) {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pickSeed returns the address of the first registered node answering. Operations changing the cluster run once
+// against it, so one failing halfway, even on a node it could not reach, is not started over from another seed.
+func pickSeed(ctx context.Context, operator cli.ClusterOperator, nodes []queries.Node) (string, int, error) {
+	seedHost, seedPort := "", 0
+	if err := trySeeds(nodes, func(host string, port int) error {
+		if _, err := operator.GetClusterNodes(ctx, host, port); err != nil {
+			return err
+		}
+		seedHost, seedPort = host, port
+		return nil
+	}); err != nil {
+		return "", 0, err
+	}
+	return seedHost, seedPort, nil
+}
+
+// operatorError names a known failure of the nodes in the message and picks a matching status instead of the fallback status.
+func operatorError(message string, status int, err error) (int, string) {
+	kind := cli.Classify(err)
+	if kind == nil {
+		return status, message
+	}
+
+	switch kind {
+	case cli.ErrUnknownNode:
+		status = http.StatusNotFound
+	case cli.ErrNodeNotEmpty, cli.ErrClusterDisabled:
+		status = http.StatusConflict
+	case cli.ErrClusterDown:
+		status = http.StatusServiceUnavailable
+	}
+
+	return status, message + ": " + kind.Error()
+}
+
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -489,6 +572,80 @@ func (a *API) CreateCluster(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 }
 
+type ImportClusterResponse struct {
+	Nodes []GetNodeResponse `json:"nodes"`
+}
+
+// ImportCluster registers a running cluster and all of its nodes from one seed node
+// POST /api/cluster/import
+func (a *API) ImportCluster(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &topology.ImportOptions{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.Name == "" || request.Host == "" || request.Port == 0 {
+		http.Error(w, "name, host and port are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Str("clusterName", request.Name).Msg("Failed to import cluster")
+		http.Error(w, "failed to import cluster", http.StatusUnauthorized)
+		return
+	}
+
+	nodes, err := a.syncer.ImportCluster(ctx, *request)
+	if err != nil {
+		log.Error().Err(err).Str("clusterName", request.Name).Msg("Failed to import cluster")
+		if errors.Is(err, topology.ErrClusterExists) || errors.Is(err, topology.ErrNodeRegistered) {
+			http.Error(w, "failed to import cluster: "+err.Error(), http.StatusConflict)
+			return
+		}
+		status, message := operatorError("failed to import cluster", http.StatusBadGateway, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	response := &ImportClusterResponse{
+		Nodes: make([]GetNodeResponse, 0, len(nodes)),
+	}
+	for _, n := range nodes {
+		response.Nodes = append(response.Nodes, GetNodeResponse{
+			ClusterName: request.Name,
+			NodeID:      n.NodeID,
+			Host:        n.Host,
+			Port:        n.Port,
+			Connected:   n.Connected,
+			Role:        n.Role,
+			MasterID:    n.MasterID,
+			Slots:       n.Slots,
+			CreatedAt:   n.CreatedAt.Time,
+			UpdatedAt:   n.UpdatedAt.Time,
+			SyncedAt:    n.SyncedAt.Time,
+		})
+	}
+
+	data, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
 type GetClusterRequest struct {
 	Name string `json:"name"`
 }
@@ -744,17 +901,13 @@ func (a *API) DeleteCluster(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-type CreateNodeRequest struct {
-	Name        string `json:"name"`
-	ClusterName string `json:"cluster_name"`
-	NodeID      string `json:"node_id"`
-	Host        string `json:"host"`
-	Port        int32  `json:"port"`
+type GetClusterSlotsRequest struct {
+	Name string `json:"name"`
 }
 
-// CreateNode creates a new node
-// POST /api/node
-func (a *API) CreateNode(w http.ResponseWriter, r *http.Request) {
+// GetClusterSlots returns the slot map of the cluster
+// GET /api/cluster/slots?name=cluster_name
+func (a *API) GetClusterSlots(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
@@ -766,85 +919,62 @@ func (a *API) CreateNode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	request := &CreateNodeRequest{}
-	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+	request := &GetClusterSlotsRequest{
+		Name: r.URL.Query().Get("name"),
 	}
 
+	var operator cli.ClusterOperator
+	var seeds []queries.Node
 	responseStatus := http.StatusOK
-	if err := a.store.VisitTx(ctx, func(ctx context.Context, q *queries.Queries) error {
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
 		_, err := q.GetSession(ctx, ck.Value)
 		if err != nil {
 			responseStatus = http.StatusUnauthorized
 			return fmt.Errorf("q.GetSession: %w", err)
 		}
 
-		_, err = q.GetCluster(ctx, request.ClusterName)
+		operator, seeds, err = a.clusterOperator(ctx, q, request.Name)
 		if err != nil {
 			responseStatus = http.StatusNotFound
-			return fmt.Errorf("q.GetCluster: %w", err)
-		}
-
-		_, err = q.GetNodeByHostPort(ctx, queries.GetNodeByHostPortParams{
-			Host: request.Host,
-			Port: request.Port,
-		})
-		if err == nil {
-			responseStatus = http.StatusConflict
-			return fmt.Errorf("q.GetNodeByHostPort: %w", err)
-		}
-
-		_, err = q.GetNodeByNodeID(ctx, queries.GetNodeByNodeIDParams{
-			NodeID: request.NodeID,
-		})
-		if err == nil {
-			responseStatus = http.StatusConflict
-			return fmt.Errorf("q.GetNodeByNodeID: %w", err)
-		}
-
-		_, err = q.CreateNode(ctx, queries.CreateNodeParams{
-			Name:   request.ClusterName,
-			NodeID: request.NodeID,
-			Host:   request.Host,
-			Port:   request.Port,
-		})
-		if err != nil {
-			responseStatus = http.StatusBadRequest
-			return fmt.Errorf("q.CreateNode: %w", err)
+			return fmt.Errorf("a.clusterOperator: %w", err)
 		}
 
 		return nil
 	}); err != nil {
-		log.Error().Err(err).Any("request", request).Msg("Failed to create node")
-		http.Error(w, "failed to create node", responseStatus)
+		log.Error().Err(err).Any("request", request).Msg("Failed to get cluster slots")
+		http.Error(w, "failed to get cluster slots", responseStatus)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	var slotMap *cli.SlotMap
+	if err := trySeeds(seeds, func(host string, port int) error {
+		slotMap, err = operator.GetSlotMap(ctx, host, port)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to get cluster slots")
+		status, message := operatorError("failed to get cluster slots", http.StatusBadGateway, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	data, _ := json.Marshal(slotMap)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
 }
 
-type GetNodeRequest struct {
-	ClusterName string `json:"cluster_name"`
-	NodeID      string `json:"node_id"`
-	Host        string `json:"host"`
-	Port        int32  `json:"port"`
+type CheckClusterRequest struct {
+	Name string `json:"name"`
 }
 
-type GetNodeResponse struct {
-	ClusterName string    `json:"name"`
-	NodeID      string    `json:"node_id"`
-	Host        string    `json:"host"`
-	Port        int32     `json:"port"`
-	Connected   bool      `json:"connected"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+type CheckClusterResponse struct {
+	Healthy  bool          `json:"healthy"`
+	Findings []cli.Finding `json:"findings"`
 }
 
-// GetNode returns the node information
-// GET /api/node?cluster_name=cluster_name&host=host&port=port
-// GET /api/node?cluster_name=cluster_name&node_id=node_id
-func (a *API) GetNode(w http.ResponseWriter, r *http.Request) {
+// CheckCluster checks the health of the cluster
+// GET /api/cluster/check?name=cluster_name
+func (a *API) CheckCluster(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
@@ -856,16 +986,12 @@ func (a *API) GetNode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	request := &GetNodeRequest{
-		ClusterName: r.URL.Query().Get("cluster_name"),
-		NodeID:      r.URL.Query().Get("node_id"),
-		Host:        r.URL.Query().Get("host"),
-	}
-	if r.URL.Query().Get("port") != "" {
-		request.Port = 0
+	request := &CheckClusterRequest{
+		Name: r.URL.Query().Get("name"),
 	}
 
-	response := &GetNodeResponse{}
+	var operator cli.ClusterOperator
+	var seeds []queries.Node
 	responseStatus := http.StatusOK
 	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
 		_, err := q.GetSession(ctx, ck.Value)
@@ -874,63 +1000,53 @@ func (a *API) GetNode(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("q.GetSession: %w", err)
 		}
 
-		resp, err := queries.Node{}, error(nil)
-		switch request.Port {
-		case 0:
-			resp, err = q.GetNodeByNodeID(ctx, queries.GetNodeByNodeIDParams{
-				NodeID: request.NodeID,
-			})
-		default:
-			resp, err = q.GetNodeByHostPort(ctx, queries.GetNodeByHostPortParams{
-				Host: request.Host,
-				Port: request.Port,
-			})
-		}
-		if err != nil {
-			responseStatus = http.StatusNotFound
-			return fmt.Errorf("q.GetNode: %w", err)
-		}
-
-		clusterInfo, err := q.GetCluster(ctx, request.ClusterName)
+		operator, seeds, err = a.clusterOperator(ctx, q, request.Name)
 		if err != nil {
 			responseStatus = http.StatusNotFound
-			return fmt.Errorf("q.GetCluster: %w", err)
+			return fmt.Errorf("a.clusterOperator: %w", err)
 		}
 
-		response.ClusterName = clusterInfo.Name
-		response.NodeID = resp.NodeID
-		response.Host = resp.Host
-		response.Port = resp.Port
-		response.Connected = resp.Connected
-		response.CreatedAt = resp.CreatedAt.Time
-		response.UpdatedAt = resp.UpdatedAt.Time
-
 		return nil
 	}); err != nil {
-		log.Error().Err(err).Any("request", request).Msg("Failed to get node")
-		http.Error(w, "failed to get node", responseStatus)
+		log.Error().Err(err).Any("request", request).Msg("Failed to check cluster")
+		http.Error(w, "failed to check cluster", responseStatus)
 		return
 	}
 
-	data, _ := json.Marshal(response)
+	var findings []cli.Finding
+	if err := trySeeds(seeds, func(host string, port int) error {
+		findings, err = operator.CheckCluster(ctx, host, port)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to check cluster")
+		status, message := operatorError("failed to check cluster", http.StatusBadGateway, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	data, _ := json.Marshal(&CheckClusterResponse{
+		Healthy:  !cli.HasErrors(findings),
+		Findings: findings,
+	})
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(data)
 	w.WriteHeader(http.StatusOK)
 }
 
-type GetNodesRequest struct {
-	ClusterName string `json:"cluster_name"`
-	Count       int32  `json:"count"`
-	Cursor      string `json:"cursor"`
+type FixClusterRequest struct {
+	Name         string `json:"name"`
+	DryRun       bool   `json:"dry_run"`
+	OrphanTarget string `json:"orphan_target"`
 }
 
-type GetNodesResponse struct {
-	Nodes []GetNodeResponse `json:"nodes"`
+type FixClusterResponse struct {
+	DryRun  bool            `json:"dry_run"`
+	Actions []cli.FixAction `json:"actions"`
 }
 
-// GetNodes returns the list of nodes
-// GET /api/nodes?cluster_name=cluster_name&count=10&cursor=cursor
-func (a *API) GetNodes(w http.ResponseWriter, r *http.Request) {
+// FixCluster repairs open and uncovered slots of the cluster
+// POST /api/cluster/fix?name=cluster_name
+func (a *API) FixCluster(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
@@ -942,16 +1058,16 @@ func (a *API) GetNodes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	request := &GetNodesRequest{
-		ClusterName: r.URL.Query().Get("cluster_name"),
-		Count:       10,
-		Cursor:      r.URL.Query().Get("cursor"),
+	request := &FixClusterRequest{
+		Name: r.URL.Query().Get("name"),
 	}
-	if r.URL.Query().Get("count") != "" {
-		request.Count = 10
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	response := make([]GetNodeResponse, 0)
+	var operator cli.ClusterOperator
+	var seeds []queries.Node
 	responseStatus := http.StatusOK
 	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
 		_, err := q.GetSession(ctx, ck.Value)
@@ -960,65 +1076,66 @@ func (a *API) GetNodes(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("q.GetSession: %w", err)
 		}
 
-		_, err = q.GetCluster(ctx, request.ClusterName)
+		operator, seeds, err = a.clusterOperator(ctx, q, request.Name)
 		if err != nil {
 			responseStatus = http.StatusNotFound
-			return fmt.Errorf("q.GetCluster: %w", err)
-		}
-
-		resp, err := ([]queries.Node)(nil), error(nil)
-		switch len(request.Cursor) {
-		case 0:
-			resp, err = q.GetNodes(ctx, queries.GetNodesParams{
-				Name:  request.ClusterName,
-				Limit: request.Count,
-			})
-		default:
-			resp, err = q.GetNodesByCursor(ctx, queries.GetNodesByCursorParams{
-				Name:   request.ClusterName,
-				NodeID: request.Cursor,
-				Limit:  request.Count,
-			})
-		}
-		if err != nil {
-			responseStatus = http.StatusInternalServerError
-			return fmt.Errorf("q.GetNodes: %w", err)
-		}
-
-		for _, r := range resp {
-			response = append(response, GetNodeResponse{
-				ClusterName: request.ClusterName,
-				NodeID:      r.NodeID,
-				Host:        r.Host,
-				Port:        r.Port,
-				Connected:   r.Connected,
-				CreatedAt:   r.CreatedAt.Time,
-				UpdatedAt:   r.UpdatedAt.Time,
-			})
+			return fmt.Errorf("a.clusterOperator: %w", err)
 		}
 
 		return nil
 	}); err != nil {
-		log.Error().Err(err).Any("request", request).Msg("Failed to get nodes")
-		http.Error(w, "failed to get nodes", responseStatus)
+		log.Error().Err(err).Any("request", request).Msg("Failed to fix cluster")
+		http.Error(w, "failed to fix cluster", responseStatus)
 		return
 	}
 
-	data, _ := json.Marshal(&GetNodesResponse{Nodes: response})
+	host, port, err := pickSeed(ctx, operator, seeds)
+	if err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to fix cluster")
+		status, message := operatorError("failed to fix cluster", http.StatusBadGateway, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	actions, err := operator.FixCluster(ctx, host, port, cli.FixOptions{
+		DryRun:       request.DryRun,
+		OrphanTarget: request.OrphanTarget,
+	})
+	if err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to fix cluster")
+		status, message := operatorError("failed to fix cluster", http.StatusBadGateway, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	data, _ := json.Marshal(&FixClusterResponse{
+		DryRun:  request.DryRun,
+		Actions: actions,
+	})
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(data)
 	w.WriteHeader(http.StatusOK)
 }
 
-type DeleteNodeRequest struct {
-	ClusterName string `json:"cluster_name"`
-	NodeID      string `json:"node_id"`
+type RebalanceClusterRequest struct {
+	Name            string             `json:"name"`
+	Weights         map[string]float64 `json:"weights"`
+	Threshold       *float64           `json:"threshold"`
+	UseEmptyMasters bool               `json:"use_empty_masters"`
+	ByData          bool               `json:"by_data"`
+	Samples         int                `json:"samples"`
+	DryRun          bool               `json:"dry_run"`
 }
 
-// DeleteNode deletes the node
-// DELETE /api/node?cluster_name=cluster_name&node_id=node_id
-func (a *API) DeleteNode(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithCancel(r.Context())
+type RebalanceClusterResponse struct {
+	DryRun bool               `json:"dry_run"`
+	Plan   *cli.RebalancePlan `json:"plan"`
+}
+
+// RebalanceCluster moves slots between the masters according to their weights
+// POST /api/cluster/rebalance?name=cluster_name
+func (a *API) RebalanceCluster(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
 	defer r.Body.Close()
@@ -1029,12 +1146,249 @@ func (a *API) DeleteNode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	request := &DeleteNodeRequest{
-		ClusterName: r.URL.Query().Get("cluster_name"),
-		NodeID:      r.URL.Query().Get("node_id"),
+	request := &RebalanceClusterRequest{
+		Name: r.URL.Query().Get("name"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts := cli.RebalanceOptions{
+		Weights:         request.Weights,
+		Threshold:       cli.DefaultRebalanceThreshold,
+		UseEmptyMasters: request.UseEmptyMasters,
+	}
+	if request.Threshold != nil {
+		opts.Threshold = *request.Threshold
+	}
+
+	var operator cli.ClusterOperator
+	var seeds []queries.Node
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		operator, seeds, err = a.clusterOperator(ctx, q, request.Name)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("a.clusterOperator: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to rebalance cluster")
+		http.Error(w, "failed to rebalance cluster", responseStatus)
+		return
+	}
+
+	host, port, err := pickSeed(ctx, operator, seeds)
+	if err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to rebalance cluster")
+		status, message := operatorError("failed to rebalance cluster", http.StatusBadGateway, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	// the plan and its moves all go through the same seed, a rebalance failing halfway is never planned again
+	nodes, err := operator.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to rebalance cluster")
+		status, message := operatorError("failed to rebalance cluster", http.StatusBadGateway, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	if request.ByData {
+		usage, err := operator.MeasureSlots(ctx, host, port, request.Samples)
+		if err != nil {
+			log.Error().Err(err).Any("request", request).Msg("Failed to rebalance cluster")
+			status, message := operatorError("failed to rebalance cluster", http.StatusBadGateway, err)
+			http.Error(w, message, status)
+			return
+		}
+		opts.SlotBytes = cli.SlotBytes(usage)
+	}
+
+	plan, err := cli.PlanRebalance(nodes, opts)
+	if err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to rebalance cluster")
+		status, message := operatorError("failed to rebalance cluster", http.StatusBadRequest, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	if !request.DryRun {
+		if err := operator.ExecuteRebalance(ctx, host, port, plan); err != nil {
+			log.Error().Err(err).Any("request", request).Msg("Failed to rebalance cluster")
+			status, message := operatorError("failed to rebalance cluster", http.StatusBadGateway, err)
+			http.Error(w, message, status)
+			return
+		}
+	}
+
+	data, _ := json.Marshal(&RebalanceClusterResponse{
+		DryRun: request.DryRun,
+		Plan:   plan,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type ScanClusterRequest struct {
+	Name string `json:"name"`
+	cli.ScanOptions
+}
+
+// ScanCluster scans the keyspace of the masters and reports the biggest keys and the keys and memory per slot
+// POST /api/cluster/scan?name=cluster_name
+func (a *API) ScanCluster(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &ScanClusterRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request.Name = r.URL.Query().Get("name")
+
+	var operator cli.ClusterOperator
+	var seeds []queries.Node
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		operator, seeds, err = a.clusterOperator(ctx, q, request.Name)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("a.clusterOperator: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to scan cluster")
+		http.Error(w, "failed to scan cluster", responseStatus)
+		return
+	}
+
+	var report *cli.KeyspaceReport
+	if err := trySeeds(seeds, func(host string, port int) error {
+		report, err = operator.ScanKeyspace(ctx, host, port, request.ScanOptions)
+		if errors.Is(err, cli.ErrNodeNotFound) {
+			responseStatus = http.StatusBadRequest
+		}
+		return err
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to scan cluster")
+		if responseStatus == http.StatusOK {
+			responseStatus = http.StatusBadGateway
+		}
+		status, message := operatorError("failed to scan cluster", responseStatus, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	data, _ := json.Marshal(report)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type CreateNodeRequest struct {
+	Name        string `json:"name"`
+	ClusterName string `json:"cluster_name"`
+	NodeID      string `json:"node_id"`
+	Host        string `json:"host"`
+	Port        int32  `json:"port"`
+}
+
+// CreateNode creates a new node
+// POST /api/node
+func (a *API) CreateNode(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &CreateNodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	responseStatus := http.StatusOK
+	if request.NodeID == "" {
+		var cluster queries.Cluster
+		var seeds []queries.Node
+		if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+			_, err := q.GetSession(ctx, ck.Value)
+			if err != nil {
+				responseStatus = http.StatusUnauthorized
+				return fmt.Errorf("q.GetSession: %w", err)
+			}
+
+			cluster, err = q.GetCluster(ctx, request.ClusterName)
+			if err != nil {
+				responseStatus = http.StatusNotFound
+				return fmt.Errorf("q.GetCluster: %w", err)
+			}
+
+			seeds, err = q.GetClusterNodes(ctx, request.ClusterName)
+			if err != nil {
+				responseStatus = http.StatusInternalServerError
+				return fmt.Errorf("q.GetClusterNodes: %w", err)
+			}
+
+			return nil
+		}); err != nil {
+			log.Error().Err(err).Any("request", request).Msg("Failed to create node")
+			http.Error(w, "failed to create node", responseStatus)
+			return
+		}
+
+		// the ID is looked up through the registered nodes, the password of the cluster never goes to the address of the request
+		if len(seeds) == 0 {
+			http.Error(w, "node_id is required for the first node of a cluster", http.StatusBadRequest)
+			return
+		}
+		node, err := topology.ResolveNode(ctx, a.newOperator(cluster.Name, cluster.Password), seeds, request.Host, int(request.Port))
+		if err != nil {
+			log.Error().Err(err).Any("request", request).Msg("Failed to create node")
+			responseStatus = http.StatusBadGateway
+			if errors.Is(err, topology.ErrUnknownAddress) {
+				responseStatus = http.StatusNotFound
+			}
+			status, message := operatorError("failed to get node id", responseStatus, err)
+			http.Error(w, message, status)
+			return
+		}
+		request.NodeID = node.ID
+	}
+
 	if err := a.store.VisitTx(ctx, func(ctx context.Context, q *queries.Queries) error {
 		_, err := q.GetSession(ctx, ck.Value)
 		if err != nil {
@@ -1048,29 +1402,993 @@ func (a *API) DeleteNode(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("q.GetCluster: %w", err)
 		}
 
-		_, err = q.GetNodeByNodeID(ctx, queries.GetNodeByNodeIDParams{
+		existing, err := q.GetNodeByNodeID(ctx, queries.GetNodeByNodeIDParams{
 			NodeID: request.NodeID,
 			Name:   request.ClusterName,
 		})
 		if err != nil {
-			responseStatus = http.StatusNotFound
-			return fmt.Errorf("q.GetNodeByNodeID: %w", err)
+			existing, err = q.GetNodeByHostPort(ctx, queries.GetNodeByHostPortParams{
+				Host: request.Host,
+				Port: request.Port,
+				Name: request.ClusterName,
+			})
 		}
+		if err == nil {
+			// a node found by the syncer is registered by lifting its candidate flag
+			if !existing.IsCandidate {
+				responseStatus = http.StatusConflict
+				return fmt.Errorf("node %s already exists", existing.NodeID)
+			}
+
+			_, err = q.SetNodeCandidate(ctx, queries.SetNodeCandidateParams{
+				IsCandidate: false,
+				ID:          existing.ID,
+			})
+			if err != nil {
+				responseStatus = http.StatusInternalServerError
+				return fmt.Errorf("q.SetNodeCandidate: %w", err)
+			}
 
-		if _, err := q.DeleteNode(ctx, queries.DeleteNodeParams{
+			return nil
+		}
+
+		_, err = q.CreateNode(ctx, queries.CreateNodeParams{
 			Name:   request.ClusterName,
 			NodeID: request.NodeID,
-		}); err != nil {
-			responseStatus = http.StatusInternalServerError
-			return fmt.Errorf("q.DeleteNode: %w", err)
+			Host:   request.Host,
+			Port:   request.Port,
+		})
+		if err != nil {
+			responseStatus = http.StatusBadRequest
+			return fmt.Errorf("q.CreateNode: %w", err)
 		}
 
 		return nil
 	}); err != nil {
-		log.Error().Err(err).Any("request", request).Msg("Failed to delete node")
-		http.Error(w, "failed to delete node", responseStatus)
+		log.Error().Err(err).Any("request", request).Msg("Failed to create node")
+		http.Error(w, "failed to create node", responseStatus)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+type GetNodeRequest struct {
+	ClusterName string `json:"cluster_name"`
+	NodeID      string `json:"node_id"`
+	Host        string `json:"host"`
+	Port        int32  `json:"port"`
+}
+
+type GetNodeResponse struct {
+	ClusterName string    `json:"name"`
+	NodeID      string    `json:"node_id"`
+	Host        string    `json:"host"`
+	Port        int32     `json:"port"`
+	Connected   bool      `json:"connected"`
+	IsCandidate bool      `json:"is_candidate"`
+	Role        string    `json:"role,omitempty"`
+	MasterID    string    `json:"master_id,omitempty"`
+	Slots       string    `json:"slots,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	SyncedAt    time.Time `json:"synced_at,omitzero"`
+}
+
+// GetNode returns the node information
+// GET /api/node?cluster_name=cluster_name&host=host&port=port
+// GET /api/node?cluster_name=cluster_name&node_id=node_id
+func (a *API) GetNode(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
 		return
 	}
 
+	request := &GetNodeRequest{
+		ClusterName: r.URL.Query().Get("cluster_name"),
+		NodeID:      r.URL.Query().Get("node_id"),
+		Host:        r.URL.Query().Get("host"),
+	}
+	if r.URL.Query().Get("port") != "" {
+		request.Port = 0
+	}
+
+	response := &GetNodeResponse{}
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		resp, err := queries.Node{}, error(nil)
+		switch request.Port {
+		case 0:
+			resp, err = q.GetNodeByNodeID(ctx, queries.GetNodeByNodeIDParams{
+				NodeID: request.NodeID,
+			})
+		default:
+			resp, err = q.GetNodeByHostPort(ctx, queries.GetNodeByHostPortParams{
+				Host: request.Host,
+				Port: request.Port,
+			})
+		}
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("q.GetNode: %w", err)
+		}
+
+		clusterInfo, err := q.GetCluster(ctx, request.ClusterName)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("q.GetCluster: %w", err)
+		}
+
+		response.ClusterName = clusterInfo.Name
+		response.NodeID = resp.NodeID
+		response.Host = resp.Host
+		response.Port = resp.Port
+		response.Connected = resp.Connected
+		response.IsCandidate = resp.IsCandidate
+		response.Role = resp.Role
+		response.MasterID = resp.MasterID
+		response.Slots = resp.Slots
+		response.CreatedAt = resp.CreatedAt.Time
+		response.UpdatedAt = resp.UpdatedAt.Time
+		response.SyncedAt = resp.SyncedAt.Time
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to get node")
+		http.Error(w, "failed to get node", responseStatus)
+		return
+	}
+
+	data, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type LocateKeyRequest struct {
+	ClusterName string `json:"cluster_name"`
+	Key         string `json:"key"`
+}
+
+// LocateKey returns the slot of the key and the master and replicas owning it
+// GET /api/node/key?cluster_name=cluster_name&key=key
+func (a *API) LocateKey(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &LocateKeyRequest{
+		ClusterName: r.URL.Query().Get("cluster_name"),
+		Key:         r.URL.Query().Get("key"),
+	}
+	if request.Key == "" {
+		http.Error(w, "no key", http.StatusBadRequest)
+		return
+	}
+
+	var operator cli.ClusterOperator
+	var seeds []queries.Node
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		operator, seeds, err = a.clusterOperator(ctx, q, request.ClusterName)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("a.clusterOperator: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to locate key")
+		http.Error(w, "failed to locate key", responseStatus)
+		return
+	}
+
+	var location *cli.KeyLocation
+	if err := trySeeds(seeds, func(host string, port int) error {
+		location, err = operator.LocateKey(ctx, host, port, request.Key)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to locate key")
+		responseStatus = http.StatusBadGateway
+		if errors.Is(err, cli.ErrSlotUnassigned) {
+			responseStatus = http.StatusNotFound
+		}
+		status, message := operatorError("failed to locate key", responseStatus, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	data, _ := json.Marshal(location)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type FailoverNodeRequest struct {
+	ClusterName string           `json:"cluster_name"`
+	Host        string           `json:"host"`
+	Port        int32            `json:"port"`
+	Mode        cli.FailoverMode `json:"mode"`
+	// TimeoutSeconds bounds the wait for the roles to switch, cli.DefaultFailoverTimeout when zero.
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+// FailoverNode promotes the replica to master of its shard
+// POST /api/node/failover
+func (a *API) FailoverNode(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &FailoverNodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch request.Mode {
+	case cli.FailoverDefault, cli.FailoverForce, cli.FailoverTakeover:
+	default:
+		http.Error(w, "unknown failover mode", http.StatusBadRequest)
+		return
+	}
+	if request.TimeoutSeconds < 0 {
+		http.Error(w, "timeout_seconds must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	var operator cli.ClusterOperator
+	var seeds []queries.Node
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		operator, seeds, err = a.clusterOperator(ctx, q, request.ClusterName)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("a.clusterOperator: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to failover node")
+		http.Error(w, "failed to failover node", responseStatus)
+		return
+	}
+
+	// the password of the cluster is only sent to a node the cluster knows
+	node, err := topology.ResolveNode(ctx, operator, seeds, request.Host, int(request.Port))
+	if err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to failover node")
+		responseStatus = http.StatusBadGateway
+		if errors.Is(err, topology.ErrUnknownAddress) {
+			responseStatus = http.StatusNotFound
+		}
+		status, message := operatorError("failed to failover node", responseStatus, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	if err := operator.Failover(ctx, node.Host, node.Port, cli.FailoverOptions{
+		Mode:    request.Mode,
+		Timeout: time.Duration(request.TimeoutSeconds) * time.Second,
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to failover node")
+		responseStatus = http.StatusBadGateway
+		switch {
+		case errors.Is(err, cli.ErrNotReplica):
+			responseStatus = http.StatusConflict
+		case errors.Is(err, cli.ErrFailoverTimeout):
+			responseStatus = http.StatusGatewayTimeout
+		}
+		status, message := operatorError("failed to failover node", responseStatus, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type GetNodesRequest struct {
+	ClusterName string `json:"cluster_name"`
+	Count       int32  `json:"count"`
+	Cursor      string `json:"cursor"`
+}
+
+type GetNodesResponse struct {
+	Nodes []GetNodeResponse `json:"nodes"`
+}
+
+// GetNodes returns the list of nodes
+// GET /api/nodes?cluster_name=cluster_name&count=10&cursor=cursor
+func (a *API) GetNodes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &GetNodesRequest{
+		ClusterName: r.URL.Query().Get("cluster_name"),
+		Count:       10,
+		Cursor:      r.URL.Query().Get("cursor"),
+	}
+	if r.URL.Query().Get("count") != "" {
+		request.Count = 10
+	}
+
+	response := make([]GetNodeResponse, 0)
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		_, err = q.GetCluster(ctx, request.ClusterName)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("q.GetCluster: %w", err)
+		}
+
+		resp, err := ([]queries.Node)(nil), error(nil)
+		switch len(request.Cursor) {
+		case 0:
+			resp, err = q.GetNodes(ctx, queries.GetNodesParams{
+				Name:  request.ClusterName,
+				Limit: request.Count,
+			})
+		default:
+			resp, err = q.GetNodesByCursor(ctx, queries.GetNodesByCursorParams{
+				Name:   request.ClusterName,
+				NodeID: request.Cursor,
+				Limit:  request.Count,
+			})
+		}
+		if err != nil {
+			responseStatus = http.StatusInternalServerError
+			return fmt.Errorf("q.GetNodes: %w", err)
+		}
+
+		for _, r := range resp {
+			response = append(response, GetNodeResponse{
+				ClusterName: request.ClusterName,
+				NodeID:      r.NodeID,
+				Host:        r.Host,
+				Port:        r.Port,
+				Connected:   r.Connected,
+				IsCandidate: r.IsCandidate,
+				Role:        r.Role,
+				MasterID:    r.MasterID,
+				Slots:       r.Slots,
+				CreatedAt:   r.CreatedAt.Time,
+				UpdatedAt:   r.UpdatedAt.Time,
+				SyncedAt:    r.SyncedAt.Time,
+			})
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to get nodes")
+		http.Error(w, "failed to get nodes", responseStatus)
+		return
+	}
+
+	data, _ := json.Marshal(&GetNodesResponse{Nodes: response})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type DeleteNodeRequest struct {
+	ClusterName string `json:"cluster_name"`
+	NodeID      string `json:"node_id"`
+}
+
+// DeleteNode deletes the node
+// DELETE /api/node?cluster_name=cluster_name&node_id=node_id
+func (a *API) DeleteNode(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &DeleteNodeRequest{
+		ClusterName: r.URL.Query().Get("cluster_name"),
+		NodeID:      r.URL.Query().Get("node_id"),
+	}
+
+	responseStatus := http.StatusOK
+	if err := a.store.VisitTx(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		_, err = q.GetCluster(ctx, request.ClusterName)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("q.GetCluster: %w", err)
+		}
+
+		_, err = q.GetNodeByNodeID(ctx, queries.GetNodeByNodeIDParams{
+			NodeID: request.NodeID,
+			Name:   request.ClusterName,
+		})
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("q.GetNodeByNodeID: %w", err)
+		}
+
+		if _, err := q.DeleteNode(ctx, queries.DeleteNodeParams{
+			Name:   request.ClusterName,
+			NodeID: request.NodeID,
+		}); err != nil {
+			responseStatus = http.StatusInternalServerError
+			return fmt.Errorf("q.DeleteNode: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to delete node")
+		http.Error(w, "failed to delete node", responseStatus)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type DecommissionNodeRequest struct {
+	ClusterName    string `json:"cluster_name"`
+	NodeID         string `json:"node_id"`
+	RemoveReplicas bool   `json:"remove_replicas"`
+}
+
+// DecommissionNode drains the node, removes it from the cluster and deletes it and its removed replicas from the registry
+// POST /api/node/decommission
+func (a *API) DecommissionNode(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &DecommissionNodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var operator cli.ClusterOperator
+	var seeds []queries.Node
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		operator, seeds, err = a.clusterOperator(ctx, q, request.ClusterName)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("a.clusterOperator: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to decommission node")
+		http.Error(w, "failed to decommission node", responseStatus)
+		return
+	}
+
+	// the node going away is no seed for its own removal, and neither are the replicas removed with it
+	leaving := map[string]bool{request.NodeID: true}
+	if request.RemoveReplicas {
+		if err := trySeeds(seeds, func(host string, port int) error {
+			nodes, err := operator.GetClusterNodes(ctx, host, port)
+			if err != nil {
+				return err
+			}
+			for _, node := range nodes {
+				if node.IsReplica() && node.MasterID == request.NodeID {
+					leaving[node.ID] = true
+				}
+			}
+			return nil
+		}); err != nil {
+			log.Error().Err(err).Any("request", request).Msg("Failed to decommission node")
+			status, message := operatorError("failed to get cluster nodes", http.StatusBadGateway, err)
+			http.Error(w, message, status)
+			return
+		}
+	}
+
+	others := make([]queries.Node, 0, len(seeds))
+	for _, seed := range seeds {
+		if !leaving[seed.NodeID] {
+			others = append(others, seed)
+		}
+	}
+
+	host, port, err := pickSeed(ctx, operator, others)
+	if err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to decommission node")
+		status, message := operatorError("failed to decommission node", http.StatusBadGateway, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	result, decommissionErr := operator.DecommissionNode(ctx, host, port, request.NodeID, cli.DecommissionOptions{
+		RemoveReplicas: request.RemoveReplicas,
+	})
+	// without a result the cluster is unchanged
+	if result == nil {
+		log.Error().Err(decommissionErr).Any("request", request).Msg("Failed to decommission node")
+		responseStatus = http.StatusBadGateway
+		if errors.Is(decommissionErr, cli.ErrNodeNotFound) {
+			responseStatus = http.StatusNotFound
+		}
+		status, message := operatorError("failed to decommission node", responseStatus, decommissionErr)
+		http.Error(w, message, status)
+		return
+	}
+
+	// the nodes removed before a failure are gone from the cluster all the same
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		for _, nodeID := range result.Removed {
+			if _, err := q.DeleteNode(ctx, queries.DeleteNodeParams{
+				Name:   request.ClusterName,
+				NodeID: nodeID,
+			}); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("q.DeleteNode: %w", err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to delete decommissioned node")
+		http.Error(w, "failed to delete decommissioned node", http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case errors.Is(decommissionErr, cli.ErrClusterUnhealthy):
+		log.Warn().Any("findings", result.Findings).Str("nodeID", request.NodeID).Msg("cluster unhealthy after decommission")
+	case decommissionErr != nil:
+		log.Error().Err(decommissionErr).Any("request", request).Strs("removed", result.Removed).Msg("Failed to decommission node")
+		status, message := operatorError("failed to decommission node", http.StatusBadGateway, decommissionErr)
+		http.Error(w, message, status)
+		return
+	}
+
+	data, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type CreateJobRequest struct {
+	ClusterName string          `json:"cluster_name"`
+	Kind        job.Kind        `json:"kind"`
+	Params      json.RawMessage `json:"params"`
+}
+
+// CreateJob starts a long-running operation in the background
+// POST /api/job
+func (a *API) CreateJob(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	request := &CreateJobRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		_, err = q.GetCluster(ctx, request.ClusterName)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("q.GetCluster: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to create job")
+		http.Error(w, "failed to create job", responseStatus)
+		return
+	}
+
+	created, err := a.jobs.Submit(ctx, request.ClusterName, request.Kind, request.Params)
+	if err != nil {
+		log.Error().Err(err).Any("request", request).Msg("Failed to create job")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, _ := json.Marshal(created)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(data)
+}
+
+// GetJob returns the job with its progress
+// GET /api/job?id=job_id
+func (a *API) GetJob(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to get job")
+		http.Error(w, "failed to get job", http.StatusUnauthorized)
+		return
+	}
+
+	found, err := a.jobs.Get(ctx, int32(id))
+	if err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to get job")
+		http.Error(w, "failed to get job", http.StatusNotFound)
+		return
+	}
+
+	data, _ := json.Marshal(found)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type GetJobsResponse struct {
+	Jobs []*job.Job `json:"jobs"`
+}
+
+// GetJobs returns the latest jobs of the cluster
+// GET /api/jobs?cluster_name=cluster_name&count=10
+func (a *API) GetJobs(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	clusterName := r.URL.Query().Get("cluster_name")
+	count := int64(10)
+	if value := r.URL.Query().Get("count"); value != "" {
+		if count, err = strconv.ParseInt(value, 10, 32); err != nil || count <= 0 {
+			http.Error(w, "invalid count", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Msg("Failed to get jobs")
+		http.Error(w, "failed to get jobs", http.StatusUnauthorized)
+		return
+	}
+
+	jobs, err := a.jobs.List(ctx, clusterName, int32(count))
+	if err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Msg("Failed to get jobs")
+		http.Error(w, "failed to get jobs", http.StatusInternalServerError)
+		return
+	}
+
+	data, _ := json.Marshal(&GetJobsResponse{Jobs: jobs})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+// CancelJob cancels the running job
+// DELETE /api/job?id=job_id
+func (a *API) CancelJob(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to cancel job")
+		http.Error(w, "failed to cancel job", http.StatusUnauthorized)
+		return
+	}
+
+	if err := a.jobs.Cancel(int32(id)); err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to cancel job")
+		http.Error(w, "failed to cancel job", http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// SyncCluster writes the live topology of the cluster to its nodes, registering unknown nodes as candidates
+// POST /api/cluster/sync?name=cluster_name
+func (a *API) SyncCluster(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	clusterName := r.URL.Query().Get("name")
+
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Msg("Failed to sync cluster")
+		http.Error(w, "failed to sync cluster", http.StatusUnauthorized)
+		return
+	}
+
+	result, err := a.syncer.SyncCluster(ctx, clusterName)
+	if err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Msg("Failed to sync cluster")
+		responseStatus := http.StatusBadGateway
+		if errors.Is(err, pgx.ErrNoRows) {
+			responseStatus = http.StatusNotFound
+		}
+		status, message := operatorError("failed to sync cluster", responseStatus, err)
+		http.Error(w, message, status)
+		return
+	}
+
+	data, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type GetSnapshotsResponse struct {
+	Snapshots []*topology.Snapshot `json:"snapshots"`
+}
+
+// GetSnapshots returns the latest topology snapshots of the cluster
+// GET /api/cluster/snapshots?name=cluster_name&count=10
+func (a *API) GetSnapshots(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	clusterName := r.URL.Query().Get("name")
+	count := int64(10)
+	if value := r.URL.Query().Get("count"); value != "" {
+		if count, err = strconv.ParseInt(value, 10, 32); err != nil || count <= 0 {
+			http.Error(w, "invalid count", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		return err
+	}); err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Msg("Failed to get snapshots")
+		http.Error(w, "failed to get snapshots", http.StatusUnauthorized)
+		return
+	}
+
+	snapshots, err := a.snapshots.List(ctx, clusterName, int32(count))
+	if err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Msg("Failed to get snapshots")
+		http.Error(w, "failed to get snapshots", http.StatusInternalServerError)
+		return
+	}
+
+	data, _ := json.Marshal(&GetSnapshotsResponse{Snapshots: snapshots})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+	w.WriteHeader(http.StatusOK)
+}
+
+type DiffSnapshotsResponse struct {
+	From    *topology.Snapshot `json:"from"`
+	To      *topology.Snapshot `json:"to"`
+	Changes []topology.Change  `json:"changes"`
+}
+
+// DiffSnapshots explains what changed in the cluster between two of its snapshots
+// GET /api/cluster/snapshots/diff?name=cluster_name&from=snapshot_id&to=snapshot_id
+func (a *API) DiffSnapshots(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	defer r.Body.Close()
+
+	ck, err := r.Cookie(CookieNameToken)
+	if err != nil {
+		http.Error(w, "no token", http.StatusBadRequest)
+		return
+	}
+
+	clusterName := r.URL.Query().Get("name")
+	fromID, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid from", http.StatusBadRequest)
+		return
+	}
+	toID, err := strconv.ParseInt(r.URL.Query().Get("to"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid to", http.StatusBadRequest)
+		return
+	}
+
+	var cluster queries.Cluster
+	responseStatus := http.StatusOK
+	if err := a.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.GetSession(ctx, ck.Value)
+		if err != nil {
+			responseStatus = http.StatusUnauthorized
+			return fmt.Errorf("q.GetSession: %w", err)
+		}
+
+		cluster, err = q.GetCluster(ctx, clusterName)
+		if err != nil {
+			responseStatus = http.StatusNotFound
+			return fmt.Errorf("q.GetCluster: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Msg("Failed to diff snapshots")
+		http.Error(w, "failed to diff snapshots", responseStatus)
+		return
+	}
+
+	getSnapshot := func(id int64) (*topology.Snapshot, error) {
+		snapshot, err := a.snapshots.Get(ctx, int32(id))
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.ClusterID != cluster.ID {
+			return nil, fmt.Errorf("snapshot %d belongs to another cluster", id)
+		}
+		return snapshot, nil
+	}
+
+	from, err := getSnapshot(fromID)
+	if err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Int64("from", fromID).Msg("Failed to diff snapshots")
+		http.Error(w, "from snapshot not found", http.StatusNotFound)
+		return
+	}
+	to, err := getSnapshot(toID)
+	if err != nil {
+		log.Error().Err(err).Str("clusterName", clusterName).Int64("to", toID).Msg("Failed to diff snapshots")
+		http.Error(w, "to snapshot not found", http.StatusNotFound)
+		return
+	}
+
+	response := &DiffSnapshotsResponse{
+		From:    from,
+		To:      to,
+		Changes: topology.Diff(from, to),
+	}
+
+	data, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
 	w.WriteHeader(http.StatusOK)
 }
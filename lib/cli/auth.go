@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// TLSConfig holds the TLS settings shared by redis-cli/valkey-cli and the native connections.
+type TLSConfig struct {
+	// CACert is the path of the CA certificate used to verify the nodes, the system pool when empty.
+	CACert string
+	// Cert and Key are the paths of the client certificate and its key for mutual TLS.
+	Cert string
+	Key  string
+	// SNI is the server name sent to and verified against the nodes, the dialed host when empty.
+	SNI                string
+	InsecureSkipVerify bool
+}
+
+// WithUser authenticates as the given ACL user instead of the default user.
+func WithUser(user string) Option {
+	return func(cli *CLI) {
+		cli.user = user
+	}
+}
+
+// WithTLS connects to the nodes over TLS, both from redis-cli/valkey-cli and natively.
+func WithTLS(config TLSConfig) Option {
+	return func(cli *CLI) {
+		cli.tls = &config
+	}
+}
+
+func (config *TLSConfig) load() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.SNI,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CACert != "" {
+		pem, err := os.ReadFile(config.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca cert: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", config.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.Cert != "" || config.Key != "" {
+		cert, err := tls.LoadX509KeyPair(config.Cert, config.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (cli *CLI) dialOptions() (DialOptions, error) {
+	opts := DialOptions{
+		User:     cli.user,
+		Password: cli.password,
+	}
+
+	if cli.tls != nil {
+		cli.tlsOnce.Do(func() {
+			cli.tlsConfig, cli.tlsErr = cli.tls.load()
+		})
+		if cli.tlsErr != nil {
+			return opts, cli.tlsErr
+		}
+		opts.TLS = cli.tlsConfig
+	}
+
+	return opts, nil
+}
+
+// connArgs are the options every redis-cli/valkey-cli invocation needs to reach and authenticate with the nodes.
+func (cli *CLI) connArgs() []string {
+	args := make([]string, 0)
+	if cli.user != "" {
+		args = append(args, "--user", cli.user)
+	}
+
+	if cli.tls != nil {
+		args = append(args, "--tls")
+		if cli.tls.CACert != "" {
+			args = append(args, "--cacert", cli.tls.CACert)
+		}
+		if cli.tls.Cert != "" {
+			args = append(args, "--cert", cli.tls.Cert)
+		}
+		if cli.tls.Key != "" {
+			args = append(args, "--key", cli.tls.Key)
+		}
+		if cli.tls.SNI != "" {
+			args = append(args, "--sni", cli.tls.SNI)
+		}
+		if cli.tls.InsecureSkipVerify {
+			args = append(args, "--insecure")
+		}
+	}
+
+	return args
+}
+
+// command prepares redis-cli/valkey-cli with args. The password is passed in REDISCLI_AUTH,
+// which both tools read, so it never shows up in the process list, the logs or the errors.
+func (cli *CLI) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, string(cli.name), append(cli.connArgs(), args...)...)
+	if cli.password != "" {
+		cmd.Env = append(os.Environ(), "REDISCLI_AUTH="+cli.password)
+	}
+	return cmd
+}
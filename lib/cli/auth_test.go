@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	c := New(Valkey, "secret", WithUser("operator"), WithTLS(TLSConfig{CACert: "ca.crt", SNI: "valkey.local", InsecureSkipVerify: true}))
+
+	cmd := c.command(context.Background(), "-h", "127.0.0.1", "-p", "7001", "cluster", "nodes")
+
+	want := []string{"valkey-cli", "--user", "operator", "--tls", "--cacert", "ca.crt", "--sni", "valkey.local", "--insecure", "-h", "127.0.0.1", "-p", "7001", "cluster", "nodes"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Errorf("Args = %v, want %v", cmd.Args, want)
+	}
+	if slices.Contains(cmd.Args, "secret") {
+		t.Error("password passed in argv")
+	}
+	if !slices.Contains(cmd.Env, "REDISCLI_AUTH=secret") {
+		t.Error("password not passed in REDISCLI_AUTH")
+	}
+
+	cmd = New(Valkey, "").command(context.Background(), "cluster", "nodes")
+	if cmd.Env != nil {
+		t.Errorf("Env = %v, want the inherited environment", cmd.Env)
+	}
+}
+
+func TestDialOptionsTLSError(t *testing.T) {
+	c := New(Native, "", WithTLS(TLSConfig{CACert: "testdata/missing.crt"}))
+
+	if _, err := c.dialOptions(); err == nil || !strings.Contains(err.Error(), "ca cert") {
+		t.Errorf("dialOptions() = %v, want ca cert error", err)
+	}
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+type FindingCode string
+
+const (
+	FindingNodeUnreachable FindingCode = "node_unreachable"
+	FindingConfigMismatch  FindingCode = "config_mismatch"
+	FindingSlotsUncovered  FindingCode = "slots_uncovered"
+	FindingSlotConflict    FindingCode = "slot_conflict"
+	FindingOpenSlot        FindingCode = "open_slot"
+	FindingOrphanReplica   FindingCode = "orphan_replica"
+	FindingNodeFailing     FindingCode = "node_failing"
+)
+
+type Finding struct {
+	Severity Severity    `json:"severity"`
+	Code     FindingCode `json:"code"`
+	NodeID   string      `json:"node_id,omitempty"`
+	Slots    SlotSet     `json:"slots,omitempty"`
+	Message  string      `json:"message"`
+}
+
+// HasErrors reports whether any of the findings has error severity.
+func HasErrors(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// configSignature summarizes which master owns which slots so views of different nodes can be compared.
+func configSignature(nodes []*ClusterNode) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if !node.IsMaster() || node.Slots.IsEmpty() {
+			continue
+		}
+		parts = append(parts, node.ID+":"+node.Slots.String())
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, "|")
+}
+
+// CheckCluster inspects the cluster like `--cluster check` does and returns what is wrong with it.
+// Warnings such as open slots or possibly failed nodes leave the cluster healthy, see HasErrors.
+func (cli *CLI) CheckCluster(ctx context.Context, host string, port int) ([]Finding, error) {
+	log.Info().Str("host", host).Int("port", port).Msg("check cluster")
+
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	findings := make([]Finding, 0)
+	signature := configSignature(nodes)
+
+	for _, node := range nodes {
+		if node.Flags.Has(FlagNoAddr) || node.Flags.Has(FlagHandshake) {
+			continue
+		}
+
+		nodeHost, nodePort, err := nodeAddress(node, host)
+		if err != nil {
+			return nil, err
+		}
+
+		view, err := cli.GetClusterNodes(ctx, nodeHost, nodePort)
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Code:     FindingNodeUnreachable,
+				NodeID:   node.ID,
+				Message:  fmt.Sprintf("node %s (%s) is unreachable: %v", node.ID, node.Address(), err),
+			})
+			continue
+		}
+
+		if configSignature(view) != signature {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Code:     FindingConfigMismatch,
+				NodeID:   node.ID,
+				Message:  fmt.Sprintf("node %s (%s) does not agree with %s:%d about the slot configuration", node.ID, node.Address(), host, port),
+			})
+		}
+
+		for _, self := range view {
+			if self.IsMyself() {
+				findings = append(findings, openSlotFindings(node.ID, self)...)
+			}
+		}
+	}
+
+	findings = append(findings, checkNodes(nodes)...)
+
+	log.Info().Int("findings", len(findings)).Msg("finish check cluster")
+
+	return findings, nil
+}
+
+// openSlotFindings reports the slots the node sees itself migrating or importing, ordered by slot.
+func openSlotFindings(nodeID string, self *ClusterNode) []Finding {
+	findings := make([]Finding, 0, len(self.Migrating)+len(self.Importing))
+
+	for _, slot := range slices.Sorted(maps.Keys(self.Migrating)) {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Code:     FindingOpenSlot,
+			NodeID:   nodeID,
+			Slots:    SlotSetOf(slot),
+			Message:  fmt.Sprintf("node %s has slot %d in migrating state to %s", nodeID, slot, self.Migrating[slot]),
+		})
+	}
+
+	for _, slot := range slices.Sorted(maps.Keys(self.Importing)) {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Code:     FindingOpenSlot,
+			NodeID:   nodeID,
+			Slots:    SlotSetOf(slot),
+			Message:  fmt.Sprintf("node %s has slot %d in importing state from %s", nodeID, slot, self.Importing[slot]),
+		})
+	}
+
+	return findings
+}
+
+// checkNodes checks the slot coverage, the health of the nodes and the masters of the replicas in one view of the cluster.
+func checkNodes(nodes []*ClusterNode) []Finding {
+	findings := make([]Finding, 0)
+
+	slotMap := NewSlotMap(nodes)
+	if unassigned := slotMap.Unassigned(); !unassigned.IsEmpty() {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Code:     FindingSlotsUncovered,
+			Slots:    unassigned,
+			Message:  fmt.Sprintf("%d of %d slots are not covered by any master: %s", unassigned.Count(), MaxSlotCount, unassigned),
+		})
+	}
+
+	conflicts := slotMap.Conflicts()
+	conflictSlots := make([]int, 0, len(conflicts))
+	for slot := range conflicts {
+		conflictSlots = append(conflictSlots, slot)
+	}
+	if len(conflictSlots) > 0 {
+		set := SlotSetOf(conflictSlots...)
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Code:     FindingSlotConflict,
+			Slots:    set,
+			Message:  fmt.Sprintf("%d slots are claimed by more than one master: %s", set.Count(), set),
+		})
+	}
+
+	byID := make(map[string]*ClusterNode, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+
+	for _, node := range nodes {
+		switch {
+		case node.Flags.Has(FlagFail):
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Code:     FindingNodeFailing,
+				NodeID:   node.ID,
+				Message:  fmt.Sprintf("node %s (%s) is flagged as failed", node.ID, node.Address()),
+			})
+		case node.Flags.Has(FlagPFail):
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Code:     FindingNodeFailing,
+				NodeID:   node.ID,
+				Message:  fmt.Sprintf("node %s (%s) is flagged as possibly failed", node.ID, node.Address()),
+			})
+		}
+
+		if !node.IsReplica() {
+			continue
+		}
+
+		master, ok := byID[node.MasterID]
+		switch {
+		case !ok:
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Code:     FindingOrphanReplica,
+				NodeID:   node.ID,
+				Message:  fmt.Sprintf("replica %s follows unknown master %s", node.ID, node.MasterID),
+			})
+		case !master.IsMaster():
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Code:     FindingOrphanReplica,
+				NodeID:   node.ID,
+				Message:  fmt.Sprintf("replica %s follows %s which is not a master", node.ID, node.MasterID),
+			})
+		case master.IsFailing():
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Code:     FindingOrphanReplica,
+				NodeID:   node.ID,
+				Message:  fmt.Sprintf("replica %s follows master %s which is failing", node.ID, node.MasterID),
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,145 @@
+package cli
+
+import "testing"
+
+func TestConfigSignature(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "b", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 8192, End: 16383}}},
+		{ID: "a", Flags: NewNodeFlags(FlagMyself, FlagMaster), Slots: SlotSet{{Start: 0, End: 8191}}},
+		{ID: "c", Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+		{ID: "d", Flags: NewNodeFlags(FlagMaster)},
+	}
+
+	if got, want := configSignature(nodes), "a:0-8191|b:8192-16383"; got != want {
+		t.Errorf("configSignature() = %q, want %q", got, want)
+	}
+
+	// another node sees the same masters in another order and with other flags
+	view := []*ClusterNode{
+		{ID: "a", Flags: NewNodeFlags(FlagMaster, FlagPFail), Slots: SlotSet{{Start: 0, End: 8191}}},
+		{ID: "b", Flags: NewNodeFlags(FlagMyself, FlagMaster), Slots: SlotSet{{Start: 8192, End: 16383}}},
+	}
+	if configSignature(view) != configSignature(nodes) {
+		t.Errorf("configSignature() = %q, want %q", configSignature(view), configSignature(nodes))
+	}
+
+	view[1].Slots = SlotSet{{Start: 8193, End: 16383}}
+	if configSignature(view) == configSignature(nodes) {
+		t.Error("configSignature() does not change with the slots")
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors(nil) {
+		t.Error("HasErrors(nil) = true")
+	}
+	if HasErrors([]Finding{{Severity: SeverityWarning}, {Severity: SeverityInfo}}) {
+		t.Error("HasErrors() of warnings = true")
+	}
+	if !HasErrors([]Finding{{Severity: SeverityWarning}, {Severity: SeverityError}}) {
+		t.Error("HasErrors() of an error = false")
+	}
+}
+
+func TestOpenSlotFindings(t *testing.T) {
+	self := &ClusterNode{
+		ID:        "a",
+		Migrating: map[int]string{300: "b", 5: "b", 100: "c"},
+		Importing: map[int]string{42: "c", 7: "b"},
+	}
+
+	for range 10 {
+		findings := openSlotFindings("a", self)
+
+		want := []string{"5", "100", "300", "7", "42"}
+		if len(findings) != len(want) {
+			t.Fatalf("openSlotFindings() = %+v", findings)
+		}
+		for i, w := range want {
+			if findings[i].Slots.String() != w || findings[i].Code != FindingOpenSlot || findings[i].Severity != SeverityWarning {
+				t.Fatalf("findings[%d] = %+v, want slot %s", i, findings[i], w)
+			}
+		}
+	}
+}
+
+func TestCheckNodes(t *testing.T) {
+	full := SlotSet{{Start: 0, End: MaxSlotCount - 1}}
+
+	tests := []struct {
+		name   string
+		nodes  []*ClusterNode
+		want   []FindingCode
+		errors bool
+	}{
+		{
+			name: "healthy",
+			nodes: []*ClusterNode{
+				{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: full},
+				{ID: "b", Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+			},
+		},
+		{
+			name: "replica of an unknown master",
+			nodes: []*ClusterNode{
+				{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: full},
+				{ID: "b", Flags: NewNodeFlags(FlagSlave), MasterID: "gone"},
+			},
+			want:   []FindingCode{FindingOrphanReplica},
+			errors: true,
+		},
+		{
+			name: "replica of a replica",
+			nodes: []*ClusterNode{
+				{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: full},
+				{ID: "b", Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+				{ID: "c", Flags: NewNodeFlags(FlagSlave), MasterID: "b"},
+			},
+			want:   []FindingCode{FindingOrphanReplica},
+			errors: true,
+		},
+		{
+			name: "replica of a possibly failed master",
+			nodes: []*ClusterNode{
+				{ID: "a", Flags: NewNodeFlags(FlagMaster, FlagPFail), Slots: full},
+				{ID: "b", Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+			},
+			want: []FindingCode{FindingNodeFailing, FindingOrphanReplica},
+		},
+		{
+			name: "failed master",
+			nodes: []*ClusterNode{
+				{ID: "a", Flags: NewNodeFlags(FlagMaster, FlagFail), Slots: full},
+			},
+			want:   []FindingCode{FindingNodeFailing},
+			errors: true,
+		},
+		{
+			name: "uncovered and conflicting slots",
+			nodes: []*ClusterNode{
+				{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 100}}},
+				{ID: "b", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 100, End: 200}}},
+			},
+			want:   []FindingCode{FindingSlotsUncovered, FindingSlotConflict},
+			errors: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := checkNodes(tt.nodes)
+
+			if len(findings) != len(tt.want) {
+				t.Fatalf("checkNodes() = %+v, want %v", findings, tt.want)
+			}
+			for i, code := range tt.want {
+				if findings[i].Code != code {
+					t.Errorf("findings[%d] = %+v, want %s", i, findings[i], code)
+				}
+			}
+			if HasErrors(findings) != tt.errors {
+				t.Errorf("HasErrors() = %v, want %v", HasErrors(findings), tt.errors)
+			}
+		})
+	}
+}
@@ -4,13 +4,13 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"errors"
+	"crypto/tls"
 	"fmt"
-	"io"
 	"math/rand"
 	"os/exec"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
@@ -19,12 +19,9 @@ const (
 	MaxSlotCount = 16384
 )
 
+// RunCommand runs command and returns its stdout, a failure or an error reply is returned as *CommandError.
 func RunCommand(ctx context.Context, command string, args []string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, command, args...)
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to run command %s %v: %w", command, args, err)
-	}
-	return []byte{}, nil
+	return runCommand(exec.CommandContext(ctx, command, args...))
 }
 
 type CliName string
@@ -32,162 +29,115 @@ type CliName string
 const (
 	Redis  CliName = "redis-cli"
 	Valkey CliName = "valkey-cli"
+	Native CliName = "native"
 )
 
+// DefaultIdleTimeout is how long an interactive command may stay silent before it is considered stalled.
+const DefaultIdleTimeout = 5 * time.Minute
+
 type CLI struct {
-	name     CliName
-	password string
+	name        CliName
+	user        string
+	password    string
+	tls         *TLSConfig
+	idleTimeout time.Duration
+	wait        bool
+	waitTimeout time.Duration
+
+	tlsOnce   sync.Once
+	tlsConfig *tls.Config
+	tlsErr    error
 }
 
-func New(name CliName, password string) *CLI {
-	return &CLI{name: name, password: password}
+type Option func(*CLI)
+
+// WithIdleTimeout sets how long Reshard waits for output before killing valkey-cli as stalled.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(cli *CLI) {
+		cli.idleTimeout = timeout
+	}
+}
+
+func New(name CliName, password string, opts ...Option) *CLI {
+	cli := &CLI{
+		name:        name,
+		password:    password,
+		idleTimeout: DefaultIdleTimeout,
+	}
+	for _, opt := range opts {
+		opt(cli)
+	}
+	return cli
 }
 
 func (cli *CLI) CreateCluster(ctx context.Context, replicas int, address ...string) error {
+	if cli.name == Native {
+		return fmt.Errorf("create cluster: %w", ErrNativeUnsupported)
+	}
+
 	args := []string{"--cluster", "create"}
 	args = append(args, address...)
 	args = append(args, "--cluster-replicas", strconv.FormatInt(int64(replicas), 10))
 
 	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("create cluster")
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
-	}
-
-	cmd := exec.CommandContext(ctx, string(cli.name), args...)
+	cmd := cli.command(ctx, args...)
 	cmd.Stdin = bytes.NewReader([]byte("yes\n"))
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run command %s %v: %w", cli.name, args, err)
+	if fn := progressFunc(ctx); fn != nil {
+		cmd.Stdout = newProgressParser("create", fn)
+	}
+	if _, err := runCommand(cmd); err != nil {
+		return err
 	}
 
 	log.Info().Msg("finish create cluster")
 
-	return nil
-}
+	if cli.wait {
+		host, port, err := splitAddress(address[0])
+		if err != nil {
+			return err
+		}
+		if err := cli.WaitForClusterOK(ctx, host, port); err != nil {
+			return err
+		}
+		return cli.WaitForConfigConsistent(ctx, host, port)
+	}
 
-type ClusterNode struct {
-	ID          string
-	Host        string
-	ClusterPort int
-	Flags       []string
-	MasterID    string
-	LinkState   string
-	Slots       []int
+	return nil
 }
 
 func (cli *CLI) GetClusterNodes(ctx context.Context, host string, port int) ([]*ClusterNode, error) {
-	args := []string{string(cli.name), "-h", host, "-p", strconv.Itoa(port), "cluster", "nodes"}
+	if cli.name == Native {
+		return cli.nativeGetClusterNodes(ctx, host, port)
+	}
+
+	args := []string{"-h", host, "-p", strconv.Itoa(port), "cluster", "nodes"}
 
 	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("get cluster nodes")
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
+	cmd := cli.command(ctx, args...)
+	resp, err := runCommand(cmd)
+	if err != nil {
+		return nil, err
 	}
 
-	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-	resp, err := cmd.Output()
+	nodes, err := ParseClusterNodes(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to run command %s %v: %w", cli.name, []string{"-h", host, "-p", strconv.Itoa(port), "cluster", "nodes"}, err)
+		return nil, fmt.Errorf("failed to parse cluster nodes: %w", err)
 	}
 
-	nodes := make([]*ClusterNode, 0)
-
-	isSlave := false
-	checkMasterID := false
-	sc := bufio.NewScanner(bytes.NewReader(resp))
-	for sc.Scan() {
-		line := strings.TrimPrefix(sc.Text(), "txt:")
-		if line == "" {
-			continue
-		}
+	log.Info().Interface("nodes", nodes).Msg("finish get cluster nodes")
 
-		node := &ClusterNode{}
-		prevIdx := 0
-		state := 0
-	loop:
-		for i := 0; i < len(line); i++ {
-			switch state {
-			case 0:
-				if line[i] == ' ' {
-					node.ID = line[prevIdx:i]
-					state = 1
-					prevIdx = i + 1
-				}
-			case 1:
-				if line[i] == '@' {
-					node.Host = line[prevIdx:i]
-					state = 2
-					prevIdx = i + 1
-				}
-			case 2:
-				if line[i] == ' ' {
-					port, _ := strconv.Atoi(line[prevIdx:i])
-					node.ClusterPort = port
-					state = 3
-					prevIdx = i + 1
-				}
-			case 3:
-				if line[i] == ' ' {
-					node.Flags = append(node.Flags, strings.Split(line[prevIdx:i], ",")...)
-				inner:
-					for _, flag := range node.Flags {
-						if flag == "slave" {
-							isSlave = true
-							break inner
-						}
-					}
-					state = 4
-					prevIdx = i + 1
-				}
-			case 4:
-				if isSlave && !checkMasterID {
-					if line[i] == ' ' {
-						checkMasterID = true
-						node.MasterID = line[prevIdx:i]
-						prevIdx = i + 1
-						continue loop
-					}
-					continue loop
-				}
-				if 'a' <= line[i] && line[i] <= 'z' || 'A' <= line[i] && line[i] <= 'Z' {
-					prevIdx = i
-					state = 5
-				}
-			case 5:
-				if line[i] == ' ' || len(line)-1 == i {
-					node.LinkState = line[prevIdx:i]
-					prevIdx = i + 1
-					state = 6
-				}
-			case 6:
-				if len(line)-1 == i {
-					i++
-					slotSet := strings.Split(line[prevIdx:i], " ")
-					fmt.Printf("slotSet: %v\n", slotSet)
-					for _, slotPair := range slotSet {
-						slots := strings.Split(slotPair, "-")
-						if len(slots) == 1 {
-							slot, _ := strconv.Atoi(strings.TrimSpace(slots[0]))
-							node.Slots = append(node.Slots, slot)
-						} else {
-							start, _ := strconv.Atoi(slots[0])
-							end, _ := strconv.Atoi(slots[1])
-							node.Slots = append(node.Slots, start, end)
-						}
-					}
-					state = 7
-				}
-			case 7:
-				break loop
-			}
-		}
+	return nodes, nil
+}
 
-		nodes = append(nodes, node)
+func (cli *CLI) GetSlotMap(ctx context.Context, host string, port int) (*SlotMap, error) {
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
 	}
 
-	log.Info().Interface("nodes", nodes).Msg("finish get cluster nodes")
-
-	return nodes, nil
+	return NewSlotMap(nodes), nil
 }
 
 func (cli *CLI) GetNoSlotNodes(ctx context.Context, host string, port int) ([]string, int, error) {
@@ -218,20 +168,28 @@ type ClusterInfo struct {
 }
 
 func (cli *CLI) GetClusterInfo(ctx context.Context, host string, port int) (*ClusterInfo, error) {
-	args := []string{string(cli.name), "-h", host, "-p", strconv.Itoa(port), "cluster", "info"}
+	if cli.name == Native {
+		return cli.nativeGetClusterInfo(ctx, host, port)
+	}
 
-	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("get cluster info")
+	args := []string{"-h", host, "-p", strconv.Itoa(port), "cluster", "info"}
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
-	}
+	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("get cluster info")
 
-	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-	resp, err := cmd.Output()
+	cmd := cli.command(ctx, args...)
+	resp, err := runCommand(cmd)
 	if err != nil {
-		return nil, fmt.Errorf("failed to run command %s %v: %w", cli.name, []string{"-h", host, "-p", strconv.Itoa(port), "cluster", "info"}, err)
+		return nil, err
 	}
 
+	info := parseClusterInfo(resp)
+
+	log.Info().Interface("info", info).Msg("finish get cluster info")
+
+	return info, nil
+}
+
+func parseClusterInfo(resp []byte) *ClusterInfo {
 	info := &ClusterInfo{}
 
 	sc := bufio.NewScanner(bytes.NewReader(resp))
@@ -269,33 +227,44 @@ func (cli *CLI) GetClusterInfo(ctx context.Context, host string, port int) (*Clu
 		}
 	}
 
-	log.Info().Interface("info", info).Msg("finish get cluster info")
-
-	return info, nil
+	return info
 }
 
 func (cli *CLI) AddNode(ctx context.Context, newNodeHost string, newNodePort int, existingNodeHost string, existingNodePort int) error {
+	if cli.name == Native {
+		return fmt.Errorf("add node: %w", ErrNativeUnsupported)
+	}
+
 	newNode := newNodeHost + ":" + strconv.FormatInt(int64(newNodePort), 10)
 	existingNode := existingNodeHost + ":" + strconv.FormatInt(int64(existingNodePort), 10)
 	args := []string{"--cluster", "add-node", newNode, existingNode}
 
 	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("add node")
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
-	}
-
-	cmd := exec.CommandContext(ctx, string(cli.name), args...)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run command %s %v: %w", cli.name, args, err)
+	cmd := cli.command(ctx, args...)
+	if _, err := runCommand(cmd); err != nil {
+		return err
 	}
 
 	log.Info().Msg("finish add node")
 
+	if cli.wait {
+		nodeID, err := cli.myID(ctx, newNodeHost, newNodePort)
+		if err != nil {
+			return fmt.Errorf("failed to get id of new node: %w", err)
+		}
+		return cli.WaitForNodeKnownByAll(ctx, existingNodeHost, existingNodePort, nodeID)
+	}
+
 	return nil
 }
 
 func (cli *CLI) Reshard(ctx context.Context, host string, port int, targetNode string, slots int, sourceNode string) error {
+	if cli.name == Native {
+		return fmt.Errorf("reshard: %w", ErrNativeUnsupported)
+	}
+
+	parent := ctx
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -303,16 +272,21 @@ func (cli *CLI) Reshard(ctx context.Context, host string, port int, targetNode s
 
 	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("reshard")
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
+	cmd := cli.command(ctx, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdin of command %s: %w", cli.name, err)
 	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdout of command %s: %w", cli.name, err)
+	}
+	cmd.Stderr = cmd.Stdout
+	// a child left behind by a killed process may hold the pipes open, Wait stops waiting for them after this
+	cmd.WaitDelay = time.Second
 
-	ipr, ipw := io.Pipe()
-	opr, opw := io.Pipe()
-
-	writeBuffer := opw
-	readBuffer := ipr
-	reactor := NewReactor(readBuffer, writeBuffer)
+	reactor := NewReactor(stdout, stdin)
+	reactor.SetIdleTimeout(cli.idleTimeout)
 	reactor.AddReaction("How many slots do you want to move", strconv.FormatInt(int64(slots), 10))
 	reactor.AddReaction("What is the receiving node ID", targetNode)
 	reactor.AddReaction("Please enter all the source node IDs", sourceNode)
@@ -320,34 +294,42 @@ func (cli *CLI) Reshard(ctx context.Context, host string, port int, targetNode s
 		reactor.AddReaction("Source node #2", "done")
 	}
 	reactor.AddReaction("Do you want to proceed with the proposed reshard plan", "yes")
+	if fn := progressFunc(ctx); fn != nil {
+		reactor.OnProgress("reshard", fn)
+	}
 
-	cmd := exec.CommandContext(ctx, string(cli.name), args...)
-	cmd.Stdin = opr
-	cmd.Stdout = ipw
-	cmd.Stderr = ipw
-	go func() {
-		reactor.React(func() {
-			cancel()
-
-			if cmd.ProcessState != nil && !cmd.ProcessState.Exited() {
-				if err := cmd.Process.Kill(); err != nil {
-					log.Error().Err(err).Msg("failed to kill process")
-				}
-			} else {
-				log.Info().Int("exitCode", cmd.ProcessState.ExitCode()).Msg("process exited")
-			}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start command %s %v: %w", cli.name, args, err)
+	}
 
-			if err := ipw.Close(); err != nil {
-				log.Error().Err(err).Msg("failed to close pipe")
-			}
+	// the reactor reads until the process closes its output, or kills it once it stalls, so Wait is only called
+	// after every read from the pipes is done
+	outcome := reactor.React(cancel)
+	waitErr := cmd.Wait()
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
 
-			if err := opr.Close(); err != nil {
-				log.Error().Err(err).Msg("failed to close pipe")
-			}
-		})
-	}()
-	if err := cmd.Run(); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, io.ErrClosedPipe) {
-		return fmt.Errorf("failed to run command %s %v: %w", cli.name, args, err)
+	log.Info().Str("outcome", string(outcome)).Int("exitCode", exitCode).Msg("reshard process exited")
+
+	if outcome != OutcomeStalled {
+		if err := parent.Err(); err != nil {
+			return fmt.Errorf("reshard interrupted: %w", err)
+		}
+		if waitErr != nil || outcome == OutcomeErrored {
+			outcome = OutcomeErrored
+		}
+	}
+
+	if outcome != OutcomeFinished {
+		return &OutcomeError{
+			Command:  string(cli.name),
+			Outcome:  outcome,
+			ExitCode: exitCode,
+			Lines:    reactor.ErrorLines(),
+			Err:      waitErr,
+		}
 	}
 
 	log.Info().Msg("finish reshard")
@@ -356,17 +338,48 @@ func (cli *CLI) Reshard(ctx context.Context, host string, port int, targetNode s
 }
 
 func (cli *CLI) ReshardAll(ctx context.Context, host string, port int) error {
-	noSlotNodes, allNodeCount, err := cli.GetNoSlotNodes(ctx, host, port)
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
 	if err != nil {
-		return fmt.Errorf("failed to get nodes without slots: %w", err)
+		return fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	// replicas never own slots, so only masters share them
+	noSlotNodes := make([]string, 0)
+	masterCount := 0
+	for _, node := range nodes {
+		if !node.IsMaster() {
+			continue
+		}
+		masterCount++
+		if len(node.Slots) == 0 {
+			noSlotNodes = append(noSlotNodes, node.ID)
+		}
 	}
 
-	log.Info().Strs("noSlotNodes", noSlotNodes).Int("allNodeCount", allNodeCount).Msg("all node information")
+	log.Info().Strs("noSlotNodes", noSlotNodes).Int("masterCount", masterCount).Msg("all node information")
 
-	slotCount := MaxSlotCount / allNodeCount
+	if masterCount == 0 {
+		return fmt.Errorf("no master found")
+	}
+
+	slotCount := MaxSlotCount / masterCount
 	for _, node := range noSlotNodes {
-		if err := cli.Reshard(ctx, host, port, node, slotCount, "all"); err != nil {
-			return fmt.Errorf("failed to reshard: %w", err)
+		nodes, err := cli.GetClusterNodes(ctx, host, port)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster nodes: %w", err)
+		}
+
+		sources := make([]*ClusterNode, 0, len(nodes))
+		for _, source := range nodes {
+			if source.ID != node && source.IsMaster() && len(source.Slots) > 0 {
+				sources = append(sources, source)
+			}
+		}
+
+		for sourceID, slots := range pickSlots(sources, slotCount) {
+			if err := cli.MigrateSlots(ctx, host, port, sourceID, node, slots); err != nil {
+				return fmt.Errorf("failed to migrate slots: %w", err)
+			}
 		}
 	}
 
@@ -376,17 +389,17 @@ func (cli *CLI) ReshardAll(ctx context.Context, host string, port int) error {
 }
 
 func (cli *CLI) ForgetNode(ctx context.Context, host string, port int, nodeID string) error {
+	if cli.name == Native {
+		return cli.nativeForgetNode(ctx, host, port, nodeID)
+	}
+
 	args := []string{"-h", host, "-p", strconv.FormatInt(int64(port), 10), "-c", "cluster", "forget", nodeID}
 
 	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("forget node")
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
-	}
-
-	cmd := exec.CommandContext(ctx, string(cli.name), args...)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run command %s %v: %w", cli.name, args, err)
+	cmd := cli.command(ctx, args...)
+	if _, err := runCommand(cmd); err != nil {
+		return err
 	}
 
 	log.Info().Msg("finish forget node")
@@ -395,17 +408,17 @@ func (cli *CLI) ForgetNode(ctx context.Context, host string, port int, nodeID st
 }
 
 func (cli *CLI) DeleteNode(ctx context.Context, host string, port int, nodeID string) error {
+	if cli.name == Native {
+		return fmt.Errorf("delete node: %w", ErrNativeUnsupported)
+	}
+
 	args := []string{"-h", host, "-p", strconv.FormatInt(int64(port), 10), "--cluster", "del-node", host + ":" + strconv.FormatInt(int64(port), 10), nodeID}
 
 	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("delete node")
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
-	}
-
-	cmd := exec.CommandContext(ctx, string(cli.name), args...)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run command %s %v: %w", cli.name, args, err)
+	cmd := cli.command(ctx, args...)
+	if _, err := runCommand(cmd); err != nil {
+		return err
 	}
 
 	log.Info().Msg("finish delete node")
@@ -414,36 +427,57 @@ func (cli *CLI) DeleteNode(ctx context.Context, host string, port int, nodeID st
 }
 
 func (cli *CLI) ReplicateNode(ctx context.Context, host string, port int, masterNodeID string) error {
+	if cli.name == Native {
+		if err := cli.nativeReplicateNode(ctx, host, port, masterNodeID); err != nil {
+			return err
+		}
+		return cli.awaitReplica(ctx, host, port, masterNodeID)
+	}
+
 	args := []string{"-h", host, "-p", strconv.FormatInt(int64(port), 10), "-c", "cluster", "replicate", masterNodeID}
 
 	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("replicate node")
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
+	cmd := cli.command(ctx, args...)
+	if _, err := runCommand(cmd); err != nil {
+		return err
 	}
 
-	cmd := exec.CommandContext(ctx, string(cli.name), args...)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run command %s %v: %w", cli.name, args, err)
+	log.Info().Msg("finish replicate node")
+
+	return cli.awaitReplica(ctx, host, port, masterNodeID)
+}
+
+// awaitReplica blocks until the node at host:port is seen as replica of masterNodeID everywhere, if the CLI was created WithWait.
+func (cli *CLI) awaitReplica(ctx context.Context, host string, port int, masterNodeID string) error {
+	if !cli.wait {
+		return nil
 	}
 
-	log.Info().Msg("finish replicate node")
+	nodeID, err := cli.myID(ctx, host, port)
+	if err != nil {
+		return fmt.Errorf("failed to get id of replica: %w", err)
+	}
 
-	return nil
+	return cli.waitForReplica(ctx, host, port, nodeID, masterNodeID)
 }
 
 func (cli *CLI) Rebalance(ctx context.Context, host string, port int) error {
+	if cli.name == Native {
+		_, err := cli.RebalanceWith(ctx, host, port, RebalanceOptions{Threshold: DefaultRebalanceThreshold})
+		return err
+	}
+
 	args := []string{"-h", host, "-p", strconv.FormatInt(int64(port), 10), "--cluster", "rebalance", host + ":" + strconv.FormatInt(int64(port), 10)}
 
 	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("rebalance")
 
-	if cli.password != "" {
-		args = append(args, "-a", cli.password)
+	cmd := cli.command(ctx, args...)
+	if fn := progressFunc(ctx); fn != nil {
+		cmd.Stdout = newProgressParser("rebalance", fn)
 	}
-
-	cmd := exec.CommandContext(ctx, string(cli.name), args...)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run command %s %v: %w", cli.name, args, err)
+	if _, err := runCommand(cmd); err != nil {
+		return err
 	}
 
 	log.Info().Msg("finish rebalance")
@@ -457,68 +491,55 @@ func (cli *CLI) ExceptNode(ctx context.Context, host string, port int, exception
 		return fmt.Errorf("failed to get cluster nodes: %w", err)
 	}
 
-	var underBorderSlot, overBorderSlot int
-	var slotCount int
-firstLoop:
-	for _, node := range nodes {
-		if len(node.Slots) == 0 {
-			continue firstLoop
-		}
-
-		for _, flag := range node.Flags {
-			if flag == "slave" {
-				continue firstLoop
-			}
+	var node *ClusterNode
+	for _, n := range nodes {
+		if n.ID == exceptionNode && n.IsMaster() {
+			node = n
 		}
+	}
 
-		if node.ID == exceptionNode {
-			underBorderSlot = node.Slots[0]
-			overBorderSlot = node.Slots[len(node.Slots)-1]
-			slotCount = node.Slots[len(node.Slots)-1] - node.Slots[0] + 1
-		}
+	if node == nil {
+		return fmt.Errorf("failed to find master node %s", exceptionNode)
 	}
 
-	var underBorderID, overBorderID string
-secondLoop:
-	for _, node := range nodes {
-		if len(node.Slots) == 0 {
-			continue secondLoop
-		}
+	slotMap := NewSlotMap(nodes)
 
-		for _, flag := range node.Flags {
-			if flag == "slave" {
-				continue secondLoop
-			}
+	// every range of the node goes to the master owning the slot right below or right above it
+	moves := make(map[string]SlotSet)
+	for _, r := range node.Slots {
+		underBorderID := slotMap.Owner(r.Start - 1)
+		overBorderID := slotMap.Owner(r.End + 1)
+		if underBorderID == exceptionNode {
+			underBorderID = ""
+		}
+		if overBorderID == exceptionNode {
+			overBorderID = ""
 		}
 
-		if node.Slots[0] == overBorderSlot+1 {
-			overBorderID = node.ID
+		if underBorderID == "" && overBorderID == "" {
+			return fmt.Errorf("failed to find underBorderID or overBorderID of slots %s", r)
 		}
 
-		if node.Slots[len(node.Slots)-1] == underBorderSlot-1 {
-			underBorderID = node.ID
+		selectedBorderNode := ""
+		if underBorderID == "" {
+			selectedBorderNode = overBorderID
+		}
+		if overBorderID == "" {
+			selectedBorderNode = underBorderID
+		}
+		if underBorderID != "" && overBorderID != "" {
+			selectedBorderNode = [2]string{underBorderID, overBorderID}[rand.Intn(2)]
 		}
-	}
 
-	if underBorderID == "" || overBorderID == "" {
-		return fmt.Errorf("failed to find underBorderID or overBorderID")
-	}
+		log.Info().Str("selectedBorderNode", selectedBorderNode).Str("slots", r.String()).Msg("except node")
 
-	selectedBorderNode := ""
-	if underBorderID == "" {
-		selectedBorderNode = overBorderID
-	}
-	if overBorderID == "" {
-		selectedBorderNode = underBorderID
-	}
-	if underBorderID != "" && overBorderID != "" {
-		selectedBorderNode = [2]string{underBorderID, overBorderID}[rand.Intn(2)]
+		moves[selectedBorderNode] = moves[selectedBorderNode].Union(SlotSet{r})
 	}
 
-	log.Info().Str("selectedBorderNode", selectedBorderNode).Int("slotCount", slotCount).Msg("except node")
-
-	if err := cli.Reshard(ctx, host, port, selectedBorderNode, slotCount, exceptionNode); err != nil {
-		return fmt.Errorf("failed to reshard: %w", err)
+	for target, slots := range moves {
+		if err := cli.MigrateSlots(ctx, host, port, exceptionNode, target, slots); err != nil {
+			return fmt.Errorf("failed to migrate slots: %w", err)
+		}
 	}
 
 	return nil
@@ -546,15 +567,8 @@ func (cli *CLI) MergeNode(ctx context.Context, host string, port int, targetNode
 
 	log.Info().Str("targetNode", targetNode.ID).Str("sourceNode", sourceNode.ID).Msg("merge node")
 
-	slotCount := 0
-	for i := 0; i < len(sourceNode.Slots); i += 2 {
-		start := sourceNode.Slots[i]
-		end := sourceNode.Slots[i+1]
-		slotCount += end - start + 1
-	}
-
-	if err := cli.Reshard(ctx, host, port, targetNode.ID, slotCount, sourceNode.ID); err != nil {
-		return fmt.Errorf("failed to reshard: %w", err)
+	if err := cli.MigrateSlots(ctx, host, port, sourceNode.ID, targetNode.ID, sourceNode.Slots); err != nil {
+		return fmt.Errorf("failed to migrate slots: %w", err)
 	}
 
 	return nil
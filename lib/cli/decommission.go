@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ForgetTTL is how long a node refuses to re-add a node it was told to forget by CLUSTER FORGET.
+const ForgetTTL = 60 * time.Second
+
+var ErrClusterUnhealthy = errors.New("cluster check reported errors")
+
+type DecommissionOptions struct {
+	// RemoveReplicas decommissions the replicas of the node as well instead of moving them to other masters.
+	RemoveReplicas bool
+}
+
+type DecommissionResult struct {
+	Moves    []SlotMove          `json:"moves,omitempty"`
+	Rehomed  []ReplicaAssignment `json:"rehomed,omitempty"`
+	Removed  []string            `json:"removed"`
+	Findings []Finding           `json:"findings"`
+}
+
+// PlanDrain hands the slots of node to the other healthy masters, always to the one owning the fewest slots,
+// so the masters end up as even as the slots of node allow.
+func PlanDrain(nodes []*ClusterNode, nodeID string) ([]SlotMove, error) {
+	var node *ClusterNode
+	targets := make([]*ClusterNode, 0)
+	for _, n := range nodes {
+		switch {
+		case n.ID == nodeID:
+			node = n
+		case n.IsMaster() && !n.IsFailing() && !n.Slots.IsEmpty():
+			targets = append(targets, n)
+		}
+	}
+	if node == nil {
+		return nil, fmt.Errorf("node %s: %w", nodeID, ErrNodeNotFound)
+	}
+	if node.Slots.IsEmpty() {
+		return []SlotMove{}, nil
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no master left to take the slots of %s", nodeID)
+	}
+	slices.SortFunc(targets, compareNodeID)
+
+	counts := make([]int, len(targets))
+	shares := make([]int, len(targets))
+	for i, target := range targets {
+		counts[i] = target.Slots.Count()
+	}
+	for range node.Slots.Count() {
+		least := 0
+		for i := range counts {
+			if counts[i] < counts[least] {
+				least = i
+			}
+		}
+		counts[least]++
+		shares[least]++
+	}
+
+	moves := make([]SlotMove, 0, len(targets))
+	remaining := node.Slots
+	for i, target := range targets {
+		if shares[i] == 0 {
+			continue
+		}
+		slots := remaining.Head(shares[i])
+		remaining = remaining.Subtract(slots)
+		moves = append(moves, SlotMove{Source: nodeID, Target: target.ID, Slots: slots})
+	}
+
+	return moves, nil
+}
+
+// DecommissionNode removes a node from the cluster: its slots are drained to the other masters, its replicas
+// follow other masters (or are removed with it), every remaining node forgets it and the node itself is reset.
+// The cluster is checked afterwards, errors found by the check are reported with ErrClusterUnhealthy.
+func (cli *CLI) DecommissionNode(ctx context.Context, host string, port int, nodeID string, opts DecommissionOptions) (*DecommissionResult, error) {
+	log.Info().Str("host", host).Int("port", port).Str("nodeID", nodeID).Msg("decommission node")
+
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	idx := slices.IndexFunc(nodes, func(n *ClusterNode) bool { return n.ID == nodeID })
+	if idx < 0 {
+		return nil, fmt.Errorf("node %s: %w", nodeID, ErrNodeNotFound)
+	}
+	node := nodes[idx]
+
+	// the node going away must not be the one the remaining steps talk to
+	if node.IsMyself() {
+		seed := slices.IndexFunc(nodes, func(n *ClusterNode) bool {
+			return n.ID != nodeID && !n.IsFailing() && !n.Flags.Has(FlagNoAddr)
+		})
+		if seed < 0 {
+			return nil, fmt.Errorf("no other node left in the cluster")
+		}
+		if node.Host == "" {
+			node.Host = host
+		}
+		host, port = nodes[seed].Host, nodes[seed].Port
+	}
+
+	result := &DecommissionResult{
+		Removed: make([]string, 0),
+	}
+
+	if node.IsMaster() && !node.Slots.IsEmpty() {
+		if node.IsFailing() {
+			return nil, fmt.Errorf("failing master %s still owns slots %s", nodeID, node.Slots)
+		}
+
+		result.Moves, err = PlanDrain(nodes, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		if err := cli.ExecuteRebalance(ctx, host, port, &RebalancePlan{Moves: result.Moves}); err != nil {
+			return result, fmt.Errorf("failed to drain slots: %w", err)
+		}
+	}
+
+	remove := make([]*ClusterNode, 0)
+	for _, replica := range nodes {
+		if !replica.IsReplica() || replica.MasterID != nodeID {
+			continue
+		}
+
+		if opts.RemoveReplicas || replica.IsFailing() {
+			remove = append(remove, replica)
+			continue
+		}
+
+		master := pickMaster(nodes, replica, nodeID)
+		if master == nil {
+			remove = append(remove, replica)
+			continue
+		}
+
+		replicaHost, replicaPort, err := nodeAddress(replica, host)
+		if err != nil {
+			return result, err
+		}
+		if _, err := cli.do(ctx, replicaHost, replicaPort, "CLUSTER", "REPLICATE", master.ID); err != nil {
+			return result, fmt.Errorf("failed to move replica %s to %s: %w", replica.ID, master.ID, err)
+		}
+		result.Rehomed = append(result.Rehomed, ReplicaAssignment{
+			ReplicaID:   replica.ID,
+			ReplicaHost: replicaHost,
+			ReplicaPort: replicaPort,
+			MasterID:    master.ID,
+			MasterHost:  master.Host,
+		})
+	}
+
+	// a replica refuses to forget its own master, so the replicas going away are removed first
+	removed := make(map[string]bool)
+	for _, n := range append(remove, node) {
+		if err := cli.removeNode(ctx, nodes, n, removed, host); err != nil {
+			return result, err
+		}
+		removed[n.ID] = true
+		result.Removed = append(result.Removed, n.ID)
+	}
+
+	if err := cli.WaitForConfigConsistent(ctx, host, port); err != nil {
+		log.Warn().Err(err).Msg("cluster config not consistent after decommission")
+	}
+
+	result.Findings, err = cli.CheckCluster(ctx, host, port)
+	if err != nil {
+		return result, fmt.Errorf("failed to check cluster: %w", err)
+	}
+	if HasErrors(result.Findings) {
+		return result, ErrClusterUnhealthy
+	}
+
+	log.Info().Str("nodeID", nodeID).Strs("removed", result.Removed).Msg("finish decommission node")
+
+	return result, nil
+}
+
+// pickMaster chooses the healthy master with the fewest replicas for replica, preferring masters on another host.
+func pickMaster(nodes []*ClusterNode, replica *ClusterNode, exceptID string) *ClusterNode {
+	replicas := make(map[string]int)
+	for _, n := range nodes {
+		if n.IsReplica() {
+			replicas[n.MasterID]++
+		}
+	}
+
+	var picked *ClusterNode
+	better := func(n *ClusterNode) bool {
+		if picked == nil {
+			return true
+		}
+		if (n.Host == replica.Host) != (picked.Host == replica.Host) {
+			return n.Host != replica.Host
+		}
+		if replicas[n.ID] != replicas[picked.ID] {
+			return replicas[n.ID] < replicas[picked.ID]
+		}
+		return n.ID < picked.ID
+	}
+	for _, n := range nodes {
+		if n.ID == exceptID || !n.IsMaster() || n.IsFailing() || n.Slots.IsEmpty() {
+			continue
+		}
+		if better(n) {
+			picked = n
+		}
+	}
+
+	return picked
+}
+
+// removeNode makes every other node forget node within ForgetTTL, so none of them gossips it back,
+// and resets node itself when it is reachable so it does not rejoin on its own.
+func (cli *CLI) removeNode(ctx context.Context, nodes []*ClusterNode, node *ClusterNode, removed map[string]bool, fallbackHost string) error {
+	log.Info().Str("nodeID", node.ID).Msg("remove node")
+
+	forgetCtx, cancel := context.WithTimeout(ctx, ForgetTTL)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+	errs := make([]error, len(nodes))
+	for i, n := range nodes {
+		if n.ID == node.ID || removed[n.ID] || n.IsFailing() || n.Flags.Has(FlagNoAddr) {
+			continue
+		}
+		host, port, err := nodeAddress(n, fallbackHost)
+		if err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cli.do(forgetCtx, host, port, "CLUSTER", "FORGET", node.ID); err != nil {
+				errs[i] = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to forget node %s: %w", node.ID, err)
+	}
+
+	if node.IsFailing() || node.Flags.Has(FlagNoAddr) {
+		return nil
+	}
+
+	host, port, err := nodeAddress(node, fallbackHost)
+	if err != nil {
+		return err
+	}
+	if _, err := cli.do(ctx, host, port, "CLUSTER", "RESET", "SOFT"); err != nil {
+		log.Warn().Err(err).Str("nodeID", node.ID).Msg("failed to reset removed node")
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrNoAuth           = errors.New("authentication required")
+	ErrWrongPass        = errors.New("invalid username or password")
+	ErrNoPerm           = errors.New("user has no permission")
+	ErrUnknownNode      = errors.New("unknown node")
+	ErrClusterDown      = errors.New("cluster is down")
+	ErrNodeNotEmpty     = errors.New("node is not empty")
+	ErrClusterDisabled  = errors.New("cluster support disabled")
+	ErrConnectionFailed = errors.New("could not connect to node")
+)
+
+// errorPatterns maps the messages printed by the servers and by redis-cli/valkey-cli to the sentinel errors.
+var errorPatterns = []struct {
+	pattern string
+	err     error
+}{
+	{"NOAUTH", ErrNoAuth},
+	{"WRONGPASS", ErrWrongPass},
+	{"invalid password", ErrWrongPass},
+	{"NOPERM", ErrNoPerm},
+	{"Unknown node", ErrUnknownNode},
+	{"CLUSTERDOWN", ErrClusterDown},
+	{"is not empty", ErrNodeNotEmpty},
+	{"cluster support disabled", ErrClusterDisabled},
+	{"Could not connect", ErrConnectionFailed},
+	{"Connection refused", ErrConnectionFailed},
+}
+
+// errorPrefixes mark a line of output as an error even if the process exited with 0, as redis-cli does for error replies.
+// The --cluster commands print "[ERR]" and "***" lines before they give up.
+var errorPrefixes = []string{"ERR ", "(error)", "[ERR]", "*** ", "NOAUTH", "WRONGPASS", "NOPERM", "CLUSTERDOWN", "Could not connect"}
+
+// isErrorLine reports whether a trimmed line of output starts with one of errorPrefixes.
+func isErrorLine(line string) bool {
+	return slices.ContainsFunc(errorPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(line, prefix)
+	})
+}
+
+func classifyMessage(message string) error {
+	for _, p := range errorPatterns {
+		if strings.Contains(message, p.pattern) {
+			return p.err
+		}
+	}
+	return nil
+}
+
+// Classify returns the sentinel error describing err, or nil when err is no known failure of a node.
+func Classify(err error) error {
+	for _, p := range errorPatterns {
+		if errors.Is(err, p.err) {
+			return p.err
+		}
+	}
+	return nil
+}
+
+// IsUnreachable reports whether err means a node could not be connected to at all.
+func IsUnreachable(err error) bool {
+	if errors.Is(err, ErrConnectionFailed) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (e RespError) Is(target error) bool {
+	return target != nil && classifyMessage(string(e)) == target
+}
+
+// CommandError is returned when redis-cli/valkey-cli fails or prints an error reply.
+type CommandError struct {
+	Command  string
+	Args     []string
+	ExitCode int
+	// Message is the error line printed by the command, Stdout and Stderr its whole output.
+	Message string
+	Stdout  string
+	Stderr  string
+	Err     error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("failed to run command %s %v", e.Command, e.Args)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if kind := classifyMessage(e.Message); kind != nil {
+		errs = append(errs, kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// runCommand runs cmd capturing its output and returns its stdout.
+// Output already directed elsewhere, like the progress parser, still receives everything.
+func runCommand(cmd *exec.Cmd) ([]byte, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	if cmd.Stdout != nil {
+		cmd.Stdout = io.MultiWriter(cmd.Stdout, stdout)
+	} else {
+		cmd.Stdout = stdout
+	}
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	message := errorLine(stderr.String(), err != nil)
+	if message == "" {
+		message = errorLine(stdout.String(), err != nil)
+	}
+	if err == nil && message == "" {
+		return stdout.Bytes(), nil
+	}
+
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+
+	return stdout.Bytes(), &CommandError{
+		Command:  cmd.Args[0],
+		Args:     cmd.Args[1:],
+		ExitCode: exitCode,
+		Message:  message,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Err:      err,
+	}
+}
+
+// errorLine returns the line of output reporting the error, preferring known failures.
+// Lines without an error prefix only count when the command failed.
+func errorLine(output string, failed bool) string {
+	prefixed, matched := "", ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		isError := isErrorLine(line)
+		known := classifyMessage(line) != nil
+
+		switch {
+		case isError && known:
+			return line
+		case isError && prefixed == "":
+			prefixed = line
+		case known && matched == "" && failed:
+			matched = line
+		}
+	}
+
+	if prefixed != "" {
+		return prefixed
+	}
+	return matched
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		output string
+		err    error
+	}{
+		{
+			name:   "success",
+			script: "echo ok",
+			output: "ok\n",
+		},
+		{
+			name:   "error reply with exit 0",
+			script: "echo 'NOAUTH Authentication required.'",
+			output: "NOAUTH Authentication required.\n",
+			err:    ErrNoAuth,
+		},
+		{
+			name:   "not empty on stdout",
+			script: "echo '>>> Adding node 127.0.0.1:7005 to cluster 127.0.0.1:7001'; echo '[ERR] Node 127.0.0.1:7005 is not empty. Either the node already knows other nodes (check with CLUSTER NODES) or contains some key in database 0.'; exit 1",
+			err:    ErrNodeNotEmpty,
+		},
+		{
+			name:   "connection refused on stderr",
+			script: "echo 'Could not connect to Valkey at 127.0.0.1:7001: Connection refused' >&2; exit 1",
+			err:    ErrConnectionFailed,
+		},
+		{
+			name:   "unknown failure",
+			script: "exit 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := RunCommand(context.Background(), "sh", []string{"-c", tt.script})
+			if tt.output != "" && string(output) != tt.output {
+				t.Errorf("output = %q, want %q", output, tt.output)
+			}
+
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+			if got := Classify(err); got != tt.err {
+				t.Errorf("Classify() = %v, want %v", got, tt.err)
+			}
+
+			if tt.name != "success" {
+				commandErr := &CommandError{}
+				if !errors.As(err, &commandErr) {
+					t.Fatalf("err = %v, want *CommandError", err)
+				}
+				if tt.name == "unknown failure" && commandErr.ExitCode != 2 {
+					t.Errorf("ExitCode = %d, want 2", commandErr.ExitCode)
+				}
+			}
+		})
+	}
+}
+
+func TestRespErrorIs(t *testing.T) {
+	err := fmt.Errorf("failed to run [CLUSTER FORGET x] on 127.0.0.1:7001: %w", RespError("ERR Unknown node x"))
+	if !errors.Is(err, ErrUnknownNode) || errors.Is(err, ErrNoAuth) {
+		t.Errorf("%v does not match its sentinel", err)
+	}
+}
+
+func TestIsUnreachable(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	read := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("failed to dial 127.0.0.1:7001: %w", dial), true},
+		{&CommandError{Message: "Could not connect to Valkey at 127.0.0.1:7001: Connection refused", Err: ErrConnectionFailed}, true},
+		{read, false},
+		{ErrNoAuth, false},
+		{RespError("IOERR error or timeout reading to target instance"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsUnreachable(tt.err); got != tt.want {
+			t.Errorf("IsUnreachable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIsErrorLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"[ERR] Nodes don't agree about configuration!", true},
+		{"*** Please fix your cluster problems before resharding", true},
+		{"(error) MOVED 3999 127.0.0.1:7002", true},
+		{"ERR unknown command 'CLUSTER'", true},
+		{"NOAUTH Authentication required.", true},
+		{"Could not connect to Valkey at 127.0.0.1:7001: Connection refused", true},
+		{"[OK] All 16384 slots covered.", false},
+		{">>> Performing Cluster Check (using node 127.0.0.1:7001)", false},
+		{"Moving slot 0 from 127.0.0.1:7001 to 127.0.0.1:7005: ", false},
+	}
+
+	for _, tt := range tests {
+		if got := isErrorLine(tt.line); got != tt.want {
+			t.Errorf("isErrorLine(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
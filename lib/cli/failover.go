@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type FailoverMode string
+
+const (
+	FailoverDefault  FailoverMode = ""
+	FailoverForce    FailoverMode = "FORCE"
+	FailoverTakeover FailoverMode = "TAKEOVER"
+)
+
+const (
+	DefaultFailoverTimeout = 60 * time.Second
+	failoverPollInterval   = 500 * time.Millisecond
+)
+
+var (
+	ErrNotReplica      = errors.New("node is not a replica")
+	ErrFailoverTimeout = errors.New("failover did not complete in time")
+)
+
+type FailoverOptions struct {
+	Mode FailoverMode `json:"mode,omitempty"`
+	// Timeout bounds the wait for the roles to switch, DefaultFailoverTimeout is used when zero.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// Failover promotes the replica at host:port with CLUSTER FAILOVER and waits until it is a master
+// and its old master became its replica. A failing old master is not waited for since it cannot follow.
+func (cli *CLI) Failover(ctx context.Context, host string, port int, opts FailoverOptions) error {
+	log.Info().Str("command", string(cli.name)).Str("host", host).Int("port", port).Str("mode", string(opts.Mode)).
+		Dur("timeout", opts.Timeout).Msg("failover")
+
+	args := []string{"CLUSTER", "FAILOVER"}
+	switch opts.Mode {
+	case FailoverDefault:
+	case FailoverForce, FailoverTakeover:
+		args = append(args, string(opts.Mode))
+	default:
+		return fmt.Errorf("unknown failover mode %q", opts.Mode)
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultFailoverTimeout
+	}
+
+	conn, err := cli.dial(ctx, host, port)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	self, oldMaster, err := failoverState(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if !self.IsReplica() {
+		return fmt.Errorf("%s:%d: %w", host, port, ErrNotReplica)
+	}
+	if oldMaster == nil {
+		return fmt.Errorf("master %s of %s: %w", self.MasterID, self.ID, ErrNodeNotFound)
+	}
+
+	if _, err := conn.Do(ctx, args...); err != nil {
+		return fmt.Errorf("failed to run %v on %s:%d: %w", args, host, port, err)
+	}
+
+	view := func(ctx context.Context) ([]*ClusterNode, error) {
+		return clusterNodes(ctx, conn)
+	}
+	if err := waitForFailover(ctx, view, self, oldMaster, timeout, failoverPollInterval); err != nil {
+		return err
+	}
+
+	log.Info().Str("nodeID", self.ID).Str("oldMaster", oldMaster.ID).Msg("finish failover")
+
+	return nil
+}
+
+// waitForFailover polls the view the promoted node has of the cluster until it is a master and its old master
+// followed it or is failing.
+func waitForFailover(ctx context.Context, view func(ctx context.Context) ([]*ClusterNode, error), self, oldMaster *ClusterNode, timeout, interval time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	oldMasterID := oldMaster.ID
+	for {
+		select {
+		case <-waitCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s is %s, old master %s is %s", ErrFailoverTimeout, self.ID, roleOf(self), oldMasterID, roleOf(oldMaster))
+		case <-ticker.C:
+		}
+
+		nodes, err := view(waitCtx)
+		if err != nil {
+			log.Warn().Err(err).Str("nodeID", self.ID).Msg("failed to get cluster nodes while waiting for failover")
+			continue
+		}
+
+		for _, node := range nodes {
+			switch {
+			case node.IsMyself():
+				self = node
+			case node.ID == oldMasterID:
+				oldMaster = node
+			}
+		}
+
+		if !self.IsMaster() {
+			continue
+		}
+		if oldMaster.IsFailing() || oldMaster.IsReplica() && oldMaster.MasterID == self.ID {
+			return nil
+		}
+	}
+}
+
+func clusterNodes(ctx context.Context, conn *Conn) ([]*ClusterNode, error) {
+	reply, err := conn.Do(ctx, "CLUSTER", "NODES")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ReplyString(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster nodes: %w", err)
+	}
+
+	return ParseClusterNodes([]byte(resp))
+}
+
+// failoverState returns the node behind conn and its master.
+func failoverState(ctx context.Context, conn *Conn) (*ClusterNode, *ClusterNode, error) {
+	nodes, err := clusterNodes(ctx, conn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	var self *ClusterNode
+	for _, node := range nodes {
+		if node.IsMyself() {
+			self = node
+		}
+	}
+	if self == nil {
+		return nil, nil, fmt.Errorf("myself: %w", ErrNodeNotFound)
+	}
+
+	for _, node := range nodes {
+		if node.ID == self.MasterID {
+			return self, node, nil
+		}
+	}
+
+	return self, nil, nil
+}
+
+func roleOf(node *ClusterNode) string {
+	switch {
+	case node.IsMaster():
+		return "master"
+	case node.IsReplica():
+		return "replica of " + node.MasterID
+	}
+	return "unknown"
+}
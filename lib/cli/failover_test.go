@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForFailover(t *testing.T) {
+	replica := func() *ClusterNode {
+		return &ClusterNode{ID: "r", Flags: NewNodeFlags(FlagMyself, FlagSlave), MasterID: "m"}
+	}
+	promoted := &ClusterNode{ID: "r", Flags: NewNodeFlags(FlagMyself, FlagMaster)}
+	oldMaster := func() *ClusterNode {
+		return &ClusterNode{ID: "m", Flags: NewNodeFlags(FlagMaster)}
+	}
+	followed := &ClusterNode{ID: "m", Flags: NewNodeFlags(FlagSlave), MasterID: "r"}
+	failing := &ClusterNode{ID: "m", Flags: NewNodeFlags(FlagMaster, FlagFail)}
+	errView := errors.New("connection reset")
+
+	tests := []struct {
+		name   string
+		views  [][]*ClusterNode
+		errs   []error
+		cancel bool
+		want   error
+	}{
+		{
+			name:  "replica takes over and old master follows",
+			views: [][]*ClusterNode{{replica(), oldMaster()}, {promoted, oldMaster()}, {promoted, followed}},
+		},
+		{
+			name:  "old master is failing",
+			views: [][]*ClusterNode{{promoted, failing}},
+		},
+		{
+			name:  "view errors are retried",
+			views: [][]*ClusterNode{nil, nil, {promoted, followed}},
+			errs:  []error{errView, errView},
+		},
+		{
+			name:  "old master never follows",
+			views: [][]*ClusterNode{{promoted, oldMaster()}},
+			want:  ErrFailoverTimeout,
+		},
+		{
+			name:  "replica never takes over",
+			views: [][]*ClusterNode{{replica(), oldMaster()}},
+			want:  ErrFailoverTimeout,
+		},
+		{
+			name:   "caller gives up",
+			views:  [][]*ClusterNode{{replica(), oldMaster()}},
+			cancel: true,
+			want:   context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			calls := 0
+			view := func(ctx context.Context) ([]*ClusterNode, error) {
+				// the last view is kept once the fake ran out of them
+				i := min(calls, len(tt.views)-1)
+				calls++
+				if tt.cancel {
+					cancel()
+				}
+				if i < len(tt.errs) {
+					return nil, tt.errs[i]
+				}
+				return tt.views[i], nil
+			}
+
+			err := waitForFailover(ctx, view, replica(), oldMaster(), 50*time.Millisecond, time.Millisecond)
+			if !errors.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+				t.Fatalf("waitForFailover() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && calls != len(tt.views) {
+				t.Errorf("views = %d, want %d", calls, len(tt.views))
+			}
+		})
+	}
+}
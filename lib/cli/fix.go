@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+type FixActionKind string
+
+const (
+	FixFinishMigration   FixActionKind = "finish_migration"
+	FixRollbackMigration FixActionKind = "rollback_migration"
+	FixAssignSlots       FixActionKind = "assign_slots"
+)
+
+type FixAction struct {
+	Kind        FixActionKind `json:"kind"`
+	Slots       SlotSet       `json:"slots"`
+	Source      string        `json:"source,omitempty"`
+	Target      string        `json:"target"`
+	Description string        `json:"description"`
+}
+
+type FixOptions struct {
+	// DryRun returns the planned actions without executing them.
+	DryRun bool
+	// OrphanTarget is the master receiving uncovered slots, the master with the fewest slots is used when empty.
+	OrphanTarget string
+	// Slots limits the repair to these open slots, other open slots and uncovered slots are left alone.
+	// The whole cluster is repaired when empty.
+	Slots SlotSet
+}
+
+type openSlot struct {
+	migrating map[string]string
+	importing map[string]string
+}
+
+// FixCluster resolves open and uncovered slots like `--cluster fix` does.
+// Migrations are finished when both sides agree and rolled back otherwise; uncovered slots are assigned to a master.
+func (cli *CLI) FixCluster(ctx context.Context, host string, port int, opts FixOptions) ([]FixAction, error) {
+	log.Info().Str("host", host).Int("port", port).Bool("dryRun", opts.DryRun).Msg("fix cluster")
+
+	m, err := cli.newMigrator(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+	defer m.close()
+
+	actions, err := m.planFix(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		return actions, nil
+	}
+
+	for _, action := range actions {
+		log.Info().Str("kind", string(action.Kind)).Str("slots", action.Slots.String()).Msg(action.Description)
+
+		if err := m.applyFix(ctx, action); err != nil {
+			return actions, fmt.Errorf("failed to %s: %w", action.Description, err)
+		}
+	}
+
+	log.Info().Int("actions", len(actions)).Msg("finish fix cluster")
+
+	return actions, nil
+}
+
+func (m *migrator) countKeys(ctx context.Context, node *ClusterNode, slot int) (int64, error) {
+	conn, err := m.conn(ctx, node)
+	if err != nil {
+		return 0, err
+	}
+
+	reply, err := conn.Do(ctx, "CLUSTER", "COUNTKEYSINSLOT", strconv.Itoa(slot))
+	if err != nil {
+		return 0, err
+	}
+
+	return ReplyInt(reply)
+}
+
+func (m *migrator) planFix(ctx context.Context, opts FixOptions) ([]FixAction, error) {
+	nodes := make([]*ClusterNode, 0, len(m.nodes))
+	for _, node := range m.nodes {
+		nodes = append(nodes, node)
+	}
+	slotMap := NewSlotMap(nodes)
+
+	// importing and migrating markers are only visible in the view a node has of itself
+	open := make(map[int]*openSlot)
+	for _, node := range nodes {
+		if !node.IsMaster() || node.Flags.Has(FlagNoAddr) {
+			continue
+		}
+
+		host, port, err := nodeAddress(node, m.seedHost)
+		if err != nil {
+			return nil, err
+		}
+
+		view, err := m.cli.GetClusterNodes(ctx, host, port)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster nodes from %s: %w", node.ID, err)
+		}
+
+		for _, self := range view {
+			if !self.IsMyself() {
+				continue
+			}
+			for slot, peer := range self.Migrating {
+				if open[slot] == nil {
+					open[slot] = &openSlot{migrating: map[string]string{}, importing: map[string]string{}}
+				}
+				open[slot].migrating[node.ID] = peer
+			}
+			for slot, peer := range self.Importing {
+				if open[slot] == nil {
+					open[slot] = &openSlot{migrating: map[string]string{}, importing: map[string]string{}}
+				}
+				open[slot].importing[node.ID] = peer
+			}
+		}
+	}
+
+	openSlots := make([]int, 0, len(open))
+	for slot := range open {
+		if opts.Slots.IsEmpty() || opts.Slots.Contains(slot) {
+			openSlots = append(openSlots, slot)
+		}
+	}
+	slices.Sort(openSlots)
+
+	actions := make([]FixAction, 0)
+	for _, slot := range openSlots {
+		action, err := m.planOpenSlot(ctx, slot, open[slot], slotMap.Owner(slot))
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+
+	uncovered := slotMap.Unassigned().Subtract(SlotSetOf(openSlots...))
+	if uncovered.IsEmpty() || !opts.Slots.IsEmpty() {
+		return actions, nil
+	}
+
+	action, err := m.planUncovered(uncovered, opts.OrphanTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(actions, action), nil
+}
+
+// planUncovered assigns the uncovered slots to target, or to the healthy master with the fewest slots when target is empty.
+func (m *migrator) planUncovered(uncovered SlotSet, target string) (FixAction, error) {
+	if target == "" {
+		fewest := -1
+		for _, node := range m.nodes {
+			if !node.IsMaster() || node.IsFailing() {
+				continue
+			}
+			if count := node.Slots.Count(); fewest < 0 || count < fewest || count == fewest && node.ID < target {
+				fewest = count
+				target = node.ID
+			}
+		}
+	}
+
+	if _, err := m.master(target); err != nil {
+		return FixAction{}, fmt.Errorf("failed to find master for uncovered slots: %w", err)
+	}
+
+	return FixAction{
+		Kind:        FixAssignSlots,
+		Slots:       uncovered,
+		Target:      target,
+		Description: fmt.Sprintf("assign uncovered slots %s to %s", uncovered, target),
+	}, nil
+}
+
+func (m *migrator) planOpenSlot(ctx context.Context, slot int, open *openSlot, owner string) (FixAction, error) {
+	slots := SlotSetOf(slot)
+
+	// the maps are walked in node ID order, so the same cluster always gets the same action
+	sources := slices.Sorted(maps.Keys(open.migrating))
+	for _, source := range sources {
+		target := open.migrating[source]
+		if open.importing[target] == source {
+			return FixAction{
+				Kind:        FixFinishMigration,
+				Slots:       slots,
+				Source:      source,
+				Target:      target,
+				Description: fmt.Sprintf("finish migration of slot %d from %s to %s", slot, source, target),
+			}, nil
+		}
+	}
+
+	for _, source := range sources {
+		target := open.migrating[source]
+
+		// the target forgot about the import, keep the keys where most of them already are
+		targetNode, err := m.master(target)
+		if err != nil {
+			return FixAction{}, err
+		}
+		keys, err := m.countKeys(ctx, targetNode, slot)
+		if err != nil {
+			return FixAction{}, fmt.Errorf("failed to count keys of slot %d on %s: %w", slot, target, err)
+		}
+		if keys > 0 {
+			return FixAction{
+				Kind:        FixFinishMigration,
+				Slots:       slots,
+				Source:      source,
+				Target:      target,
+				Description: fmt.Sprintf("finish migration of slot %d from %s to %s which already holds %d keys", slot, source, target, keys),
+			}, nil
+		}
+
+		return FixAction{
+			Kind:        FixRollbackMigration,
+			Slots:       slots,
+			Source:      target,
+			Target:      source,
+			Description: fmt.Sprintf("roll back migration of slot %d from %s to %s", slot, source, target),
+		}, nil
+	}
+
+	for _, importer := range slices.Sorted(maps.Keys(open.importing)) {
+		source := open.importing[importer]
+		if owner == "" {
+			return FixAction{
+				Kind:        FixAssignSlots,
+				Slots:       slots,
+				Target:      importer,
+				Description: fmt.Sprintf("assign uncovered slot %d to %s which is importing it", slot, importer),
+			}, nil
+		}
+
+		return FixAction{
+			Kind:        FixRollbackMigration,
+			Slots:       slots,
+			Source:      importer,
+			Target:      owner,
+			Description: fmt.Sprintf("roll back import of slot %d on %s from %s, keys go back to owner %s", slot, importer, source, owner),
+		}, nil
+	}
+
+	return FixAction{}, fmt.Errorf("slot %d is not open", slot)
+}
+
+func (m *migrator) applyFix(ctx context.Context, action FixAction) error {
+	switch action.Kind {
+	case FixFinishMigration:
+		return m.migrate(ctx, action.Source, action.Target, action.Slots)
+	case FixRollbackMigration:
+		return m.rollback(ctx, action)
+	case FixAssignSlots:
+		return m.assign(ctx, action)
+	}
+	return fmt.Errorf("unknown fix action %s", action.Kind)
+}
+
+// rollback closes the slot on both sides and returns keys stored on action.Source to the owner action.Target.
+func (m *migrator) rollback(ctx context.Context, action FixAction) error {
+	source, err := m.master(action.Source)
+	if err != nil {
+		return err
+	}
+
+	owner, err := m.master(action.Target)
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range action.Slots.Slots() {
+		slotStr := strconv.Itoa(slot)
+		for _, node := range []*ClusterNode{source, owner} {
+			conn, err := m.conn(ctx, node)
+			if err != nil {
+				return err
+			}
+			if _, err := conn.Do(ctx, "CLUSTER", "SETSLOT", slotStr, "STABLE"); err != nil {
+				return &MigrationError{Slot: slot, Source: source.ID, Target: owner.ID, Step: "set stable", Err: err}
+			}
+		}
+
+		if _, err := m.drainKeys(ctx, source, owner, slot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *migrator) assign(ctx context.Context, action FixAction) error {
+	target, err := m.master(action.Target)
+	if err != nil {
+		return err
+	}
+
+	conn, err := m.conn(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	slots := action.Slots.Slots()
+	for _, slot := range slots {
+		if _, err := conn.Do(ctx, "CLUSTER", "SETSLOT", strconv.Itoa(slot), "STABLE"); err != nil {
+			return &MigrationError{Slot: slot, Target: target.ID, Step: "set stable", Err: err}
+		}
+	}
+
+	for start := 0; start < len(slots); start += DefaultMigrateBatch {
+		batch := slots[start:min(start+DefaultMigrateBatch, len(slots))]
+		args := make([]string, 0, len(batch)+2)
+		args = append(args, "CLUSTER", "ADDSLOTS")
+		for _, slot := range batch {
+			args = append(args, strconv.Itoa(slot))
+		}
+		if _, err := conn.Do(ctx, args...); err != nil {
+			return &MigrationError{Slot: batch[0], Target: target.ID, Step: "add slots", Err: err}
+		}
+	}
+
+	// make the new owner win against stale views of the slots
+	if _, err := conn.Do(ctx, "CLUSTER", "BUMPEPOCH"); err != nil {
+		log.Warn().Err(err).Str("nodeID", target.ID).Msg("failed to bump epoch")
+	}
+
+	return nil
+}
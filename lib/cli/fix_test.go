@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeNode answers the commands sent over a pipe with reply and records them.
+type fakeNode struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+func (f *fakeNode) Commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.commands)
+}
+
+func newFakeNode(t *testing.T, reply func(args []string) string) (*Conn, *fakeNode) {
+	t.Helper()
+
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+
+	node := &fakeNode{}
+	go func() {
+		defer server.Close()
+
+		r := bufio.NewReader(server)
+		for {
+			request, err := readReply(r)
+			if err != nil {
+				return
+			}
+			args, _ := ReplyStrings(request)
+
+			node.mu.Lock()
+			node.commands = append(node.commands, strings.Join(args, " "))
+			node.mu.Unlock()
+
+			if _, err := server.Write([]byte(reply(args))); err != nil {
+				return
+			}
+		}
+	}()
+
+	return &Conn{
+		conn:   client,
+		reader: bufio.NewReader(client),
+		writer: bufio.NewWriter(client),
+		proto:  2,
+	}, node
+}
+
+func fixMigrator(t *testing.T, keys map[string]string) (*migrator, map[string]*fakeNode) {
+	t.Helper()
+
+	m := &migrator{
+		cli:      New(Native, ""),
+		seedHost: "127.0.0.1",
+		nodes: map[string]*ClusterNode{
+			"a": {ID: "a", Port: 7001, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 99}}},
+			"b": {ID: "b", Port: 7002, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 100, End: 149}}},
+			"c": {ID: "c", Port: 7003, Flags: NewNodeFlags(FlagMaster, FlagFail)},
+			"d": {ID: "d", Port: 7004, Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+			"e": {ID: "e", Port: 7005, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 150, End: 199}}},
+		},
+		conns: make(map[string]*Conn),
+	}
+
+	fakes := make(map[string]*fakeNode)
+	for id := range m.nodes {
+		count := keys[id]
+		if count == "" {
+			count = "0"
+		}
+		conn, fake := newFakeNode(t, func(args []string) string {
+			switch strings.Join(args[:min(2, len(args))], " ") {
+			case "CLUSTER COUNTKEYSINSLOT":
+				return ":" + count + "\r\n"
+			case "CLUSTER GETKEYSINSLOT":
+				// the keys of the node move with the first batch
+				if count != "0" {
+					count = "0"
+					return "*1\r\n$3\r\nkey\r\n"
+				}
+				return "*0\r\n"
+			}
+			return "+OK\r\n"
+		})
+		m.conns[id] = conn
+		fakes[id] = fake
+	}
+
+	return m, fakes
+}
+
+func TestPlanOpenSlot(t *testing.T) {
+	tests := []struct {
+		name      string
+		migrating map[string]string
+		importing map[string]string
+		owner     string
+		keys      map[string]string
+		want      FixAction
+	}{
+		{
+			name:      "both sides agree",
+			migrating: map[string]string{"a": "b"},
+			importing: map[string]string{"b": "a"},
+			owner:     "a",
+			want:      FixAction{Kind: FixFinishMigration, Source: "a", Target: "b"},
+		},
+		{
+			name:      "target forgot the import and holds keys",
+			migrating: map[string]string{"a": "b"},
+			owner:     "a",
+			keys:      map[string]string{"b": "3"},
+			want:      FixAction{Kind: FixFinishMigration, Source: "a", Target: "b"},
+		},
+		{
+			name:      "target forgot the import and holds none",
+			migrating: map[string]string{"a": "b"},
+			owner:     "a",
+			want:      FixAction{Kind: FixRollbackMigration, Source: "b", Target: "a"},
+		},
+		{
+			name:      "agreeing pair wins over a stale marker",
+			migrating: map[string]string{"a": "e", "b": "e"},
+			importing: map[string]string{"e": "b"},
+			owner:     "b",
+			want:      FixAction{Kind: FixFinishMigration, Source: "b", Target: "e"},
+		},
+		{
+			name:      "several stale markers are decided in node order",
+			migrating: map[string]string{"e": "b", "a": "b"},
+			owner:     "a",
+			want:      FixAction{Kind: FixRollbackMigration, Source: "b", Target: "a"},
+		},
+		{
+			name:      "importing only with an owner",
+			importing: map[string]string{"b": "a"},
+			owner:     "a",
+			want:      FixAction{Kind: FixRollbackMigration, Source: "b", Target: "a"},
+		},
+		{
+			name:      "importing only without an owner",
+			importing: map[string]string{"e": "a", "b": "a"},
+			want:      FixAction{Kind: FixAssignSlots, Target: "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := fixMigrator(t, tt.keys)
+
+			open := &openSlot{migrating: map[string]string{}, importing: map[string]string{}}
+			for k, v := range tt.migrating {
+				open.migrating[k] = v
+			}
+			for k, v := range tt.importing {
+				open.importing[k] = v
+			}
+
+			got, err := m.planOpenSlot(context.Background(), 5, open, tt.owner)
+			if err != nil {
+				t.Fatalf("planOpenSlot() error = %v", err)
+			}
+			if got.Kind != tt.want.Kind || got.Source != tt.want.Source || got.Target != tt.want.Target {
+				t.Errorf("planOpenSlot() = %s %s -> %s, want %s %s -> %s", got.Kind, got.Source, got.Target, tt.want.Kind, tt.want.Source, tt.want.Target)
+			}
+			if got.Slots.String() != "5" {
+				t.Errorf("slots = %s, want 5", got.Slots)
+			}
+		})
+	}
+}
+
+func TestPlanUncovered(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   string
+		err    bool
+	}{
+		{"fewest slots with ties by id", "", "b", false},
+		{"orphan target", "a", "a", false},
+		{"orphan target is a replica", "d", "", true},
+		{"orphan target is unknown", "x", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := fixMigrator(t, nil)
+
+			got, err := m.planUncovered(SlotSet{{Start: 200, End: 16383}}, tt.target)
+			if (err != nil) != tt.err {
+				t.Fatalf("planUncovered() error = %v, want error %v", err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+			if got.Kind != FixAssignSlots || got.Target != tt.want || got.Slots.String() != "200-16383" {
+				t.Errorf("planUncovered() = %+v, want assign 200-16383 to %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyFix(t *testing.T) {
+	tests := []struct {
+		name   string
+		action FixAction
+		keys   map[string]string
+		want   map[string][]string
+	}{
+		{
+			name:   "assign",
+			action: FixAction{Kind: FixAssignSlots, Slots: SlotSet{{Start: 5, End: 6}}, Target: "b"},
+			want: map[string][]string{
+				"b": {"CLUSTER SETSLOT 5 STABLE", "CLUSTER SETSLOT 6 STABLE", "CLUSTER ADDSLOTS 5 6", "CLUSTER BUMPEPOCH"},
+			},
+		},
+		{
+			name:   "rollback",
+			action: FixAction{Kind: FixRollbackMigration, Slots: SlotSetOf(5), Source: "b", Target: "a"},
+			keys:   map[string]string{"b": "1"},
+			want: map[string][]string{
+				"a": {"CLUSTER SETSLOT 5 STABLE"},
+				"b": {
+					"CLUSTER SETSLOT 5 STABLE",
+					"CLUSTER GETKEYSINSLOT 5 100",
+					"MIGRATE 127.0.0.1 7001  0 60000 KEYS key",
+					"CLUSTER GETKEYSINSLOT 5 100",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, fakes := fixMigrator(t, tt.keys)
+
+			if err := m.applyFix(context.Background(), tt.action); err != nil {
+				t.Fatalf("applyFix() error = %v", err)
+			}
+
+			for id, fake := range fakes {
+				if got := fake.Commands(); !reflect.DeepEqual(got, tt.want[id]) && (len(got) > 0 || len(tt.want[id]) > 0) {
+					t.Errorf("commands of %s = %q, want %q", id, got, tt.want[id])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultSnapshotParallelism is how many nodes GetClusterSnapshot queries at once when no parallelism is given.
+const DefaultSnapshotParallelism = 8
+
+type ServerInfo struct {
+	Version         string `json:"version"`
+	Mode            string `json:"mode"`
+	OS              string `json:"os"`
+	ProcessID       int64  `json:"process_id"`
+	TCPPort         int64  `json:"tcp_port"`
+	UptimeInSeconds int64  `json:"uptime_in_seconds"`
+	ConfigFile      string `json:"config_file,omitempty"`
+}
+
+type ClientsInfo struct {
+	ConnectedClients   int64 `json:"connected_clients"`
+	ClusterConnections int64 `json:"cluster_connections"`
+	MaxClients         int64 `json:"maxclients"`
+	BlockedClients     int64 `json:"blocked_clients"`
+}
+
+type MemoryInfo struct {
+	UsedMemory            int64   `json:"used_memory"`
+	UsedMemoryRSS         int64   `json:"used_memory_rss"`
+	UsedMemoryPeak        int64   `json:"used_memory_peak"`
+	UsedMemoryDataset     int64   `json:"used_memory_dataset"`
+	MaxMemory             int64   `json:"maxmemory"`
+	MaxMemoryPolicy       string  `json:"maxmemory_policy"`
+	MemFragmentationRatio float64 `json:"mem_fragmentation_ratio"`
+}
+
+type PersistenceInfo struct {
+	Loading                 bool   `json:"loading"`
+	RDBChangesSinceLastSave int64  `json:"rdb_changes_since_last_save"`
+	RDBBgsaveInProgress     bool   `json:"rdb_bgsave_in_progress"`
+	RDBLastSaveTime         int64  `json:"rdb_last_save_time"`
+	RDBLastBgsaveStatus     string `json:"rdb_last_bgsave_status"`
+	AOFEnabled              bool   `json:"aof_enabled"`
+	AOFRewriteInProgress    bool   `json:"aof_rewrite_in_progress"`
+	AOFLastWriteStatus      string `json:"aof_last_write_status"`
+}
+
+type StatsInfo struct {
+	TotalConnectionsReceived int64   `json:"total_connections_received"`
+	TotalCommandsProcessed   int64   `json:"total_commands_processed"`
+	InstantaneousOpsPerSec   int64   `json:"instantaneous_ops_per_sec"`
+	TotalNetInputBytes       int64   `json:"total_net_input_bytes"`
+	TotalNetOutputBytes      int64   `json:"total_net_output_bytes"`
+	InstantaneousInputKbps   float64 `json:"instantaneous_input_kbps"`
+	InstantaneousOutputKbps  float64 `json:"instantaneous_output_kbps"`
+	RejectedConnections      int64   `json:"rejected_connections"`
+	ExpiredKeys              int64   `json:"expired_keys"`
+	EvictedKeys              int64   `json:"evicted_keys"`
+	KeyspaceHits             int64   `json:"keyspace_hits"`
+	KeyspaceMisses           int64   `json:"keyspace_misses"`
+}
+
+type ReplicaInfo struct {
+	IP     string `json:"ip"`
+	Port   int64  `json:"port"`
+	State  string `json:"state"`
+	Offset int64  `json:"offset"`
+	Lag    int64  `json:"lag"`
+}
+
+type ReplicationInfo struct {
+	Role             string        `json:"role"`
+	ConnectedSlaves  int64         `json:"connected_slaves"`
+	Replicas         []ReplicaInfo `json:"replicas,omitempty"`
+	MasterHost       string        `json:"master_host,omitempty"`
+	MasterPort       int64         `json:"master_port,omitempty"`
+	MasterLinkStatus string        `json:"master_link_status,omitempty"`
+	MasterReplOffset int64         `json:"master_repl_offset"`
+}
+
+type CPUInfo struct {
+	UsedCPUSys  float64 `json:"used_cpu_sys"`
+	UsedCPUUser float64 `json:"used_cpu_user"`
+}
+
+type KeyspaceInfo struct {
+	DB      int   `json:"db"`
+	Keys    int64 `json:"keys"`
+	Expires int64 `json:"expires"`
+	AvgTTL  int64 `json:"avg_ttl"`
+}
+
+// NodeInfo is the output of INFO split into its sections.
+type NodeInfo struct {
+	Server      ServerInfo      `json:"server"`
+	Clients     ClientsInfo     `json:"clients"`
+	Memory      MemoryInfo      `json:"memory"`
+	Persistence PersistenceInfo `json:"persistence"`
+	Stats       StatsInfo       `json:"stats"`
+	Replication ReplicationInfo `json:"replication"`
+	CPU         CPUInfo         `json:"cpu"`
+	Keyspace    []KeyspaceInfo  `json:"keyspace"`
+}
+
+// Keys returns the number of keys over all databases.
+func (i *NodeInfo) Keys() int64 {
+	keys := int64(0)
+	for _, db := range i.Keyspace {
+		keys += db.Keys
+	}
+	return keys
+}
+
+type infoFields map[string]string
+
+func (f infoFields) str(key string) string {
+	return f[key]
+}
+
+func (f infoFields) int(key string) int64 {
+	v, _ := strconv.ParseInt(f[key], 10, 64)
+	return v
+}
+
+func (f infoFields) float(key string) float64 {
+	v, _ := strconv.ParseFloat(f[key], 64)
+	return v
+}
+
+func (f infoFields) bool(key string) bool {
+	return f[key] == "1"
+}
+
+// parseInfoList parses the comma separated key=value pairs of a replica or keyspace line.
+func parseInfoList(value string) infoFields {
+	fields := make(infoFields)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if ok {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+func parseNodeInfo(resp []byte) *NodeInfo {
+	sections := make(map[string]infoFields)
+	section := ""
+
+	sc := bufio.NewScanner(strings.NewReader(string(resp)))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			section = strings.ToLower(strings.TrimSpace(line[1:]))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if sections[section] == nil {
+			sections[section] = make(infoFields)
+		}
+		sections[section][key] = value
+	}
+
+	server := sections["server"]
+	clients := sections["clients"]
+	memory := sections["memory"]
+	persistence := sections["persistence"]
+	stats := sections["stats"]
+	replication := sections["replication"]
+	cpu := sections["cpu"]
+
+	info := &NodeInfo{
+		Server: ServerInfo{
+			Version:         server.str("redis_version"),
+			Mode:            server.str("redis_mode"),
+			OS:              server.str("os"),
+			ProcessID:       server.int("process_id"),
+			TCPPort:         server.int("tcp_port"),
+			UptimeInSeconds: server.int("uptime_in_seconds"),
+			ConfigFile:      server.str("config_file"),
+		},
+		Clients: ClientsInfo{
+			ConnectedClients:   clients.int("connected_clients"),
+			ClusterConnections: clients.int("cluster_connections"),
+			MaxClients:         clients.int("maxclients"),
+			BlockedClients:     clients.int("blocked_clients"),
+		},
+		Memory: MemoryInfo{
+			UsedMemory:            memory.int("used_memory"),
+			UsedMemoryRSS:         memory.int("used_memory_rss"),
+			UsedMemoryPeak:        memory.int("used_memory_peak"),
+			UsedMemoryDataset:     memory.int("used_memory_dataset"),
+			MaxMemory:             memory.int("maxmemory"),
+			MaxMemoryPolicy:       memory.str("maxmemory_policy"),
+			MemFragmentationRatio: memory.float("mem_fragmentation_ratio"),
+		},
+		Persistence: PersistenceInfo{
+			Loading:                 persistence.bool("loading"),
+			RDBChangesSinceLastSave: persistence.int("rdb_changes_since_last_save"),
+			RDBBgsaveInProgress:     persistence.bool("rdb_bgsave_in_progress"),
+			RDBLastSaveTime:         persistence.int("rdb_last_save_time"),
+			RDBLastBgsaveStatus:     persistence.str("rdb_last_bgsave_status"),
+			AOFEnabled:              persistence.bool("aof_enabled"),
+			AOFRewriteInProgress:    persistence.bool("aof_rewrite_in_progress"),
+			AOFLastWriteStatus:      persistence.str("aof_last_write_status"),
+		},
+		Stats: StatsInfo{
+			TotalConnectionsReceived: stats.int("total_connections_received"),
+			TotalCommandsProcessed:   stats.int("total_commands_processed"),
+			InstantaneousOpsPerSec:   stats.int("instantaneous_ops_per_sec"),
+			TotalNetInputBytes:       stats.int("total_net_input_bytes"),
+			TotalNetOutputBytes:      stats.int("total_net_output_bytes"),
+			InstantaneousInputKbps:   stats.float("instantaneous_input_kbps"),
+			InstantaneousOutputKbps:  stats.float("instantaneous_output_kbps"),
+			RejectedConnections:      stats.int("rejected_connections"),
+			ExpiredKeys:              stats.int("expired_keys"),
+			EvictedKeys:              stats.int("evicted_keys"),
+			KeyspaceHits:             stats.int("keyspace_hits"),
+			KeyspaceMisses:           stats.int("keyspace_misses"),
+		},
+		Replication: ReplicationInfo{
+			Role:             replication.str("role"),
+			ConnectedSlaves:  replication.int("connected_slaves"),
+			MasterHost:       replication.str("master_host"),
+			MasterPort:       replication.int("master_port"),
+			MasterLinkStatus: replication.str("master_link_status"),
+			MasterReplOffset: replication.int("master_repl_offset"),
+		},
+		CPU: CPUInfo{
+			UsedCPUSys:  cpu.float("used_cpu_sys"),
+			UsedCPUUser: cpu.float("used_cpu_user"),
+		},
+		Keyspace: make([]KeyspaceInfo, 0),
+	}
+
+	// valkey reports its own version and mode next to the redis compatible fields
+	if v := server.str("valkey_version"); v != "" {
+		info.Server.Version = v
+	}
+	if v := server.str("server_mode"); v != "" {
+		info.Server.Mode = v
+	}
+
+	for i := int64(0); i < info.Replication.ConnectedSlaves; i++ {
+		fields := parseInfoList(replication.str("slave" + strconv.FormatInt(i, 10)))
+		info.Replication.Replicas = append(info.Replication.Replicas, ReplicaInfo{
+			IP:     fields.str("ip"),
+			Port:   fields.int("port"),
+			State:  fields.str("state"),
+			Offset: fields.int("offset"),
+			Lag:    fields.int("lag"),
+		})
+	}
+
+	for key, value := range sections["keyspace"] {
+		db, err := strconv.Atoi(strings.TrimPrefix(key, "db"))
+		if err != nil {
+			continue
+		}
+		fields := parseInfoList(value)
+		info.Keyspace = append(info.Keyspace, KeyspaceInfo{
+			DB:      db,
+			Keys:    fields.int("keys"),
+			Expires: fields.int("expires"),
+			AvgTTL:  fields.int("avg_ttl"),
+		})
+	}
+	slices.SortFunc(info.Keyspace, func(a, b KeyspaceInfo) int {
+		return a.DB - b.DB
+	})
+
+	return info
+}
+
+func (cli *CLI) GetNodeInfo(ctx context.Context, host string, port int) (*NodeInfo, error) {
+	if cli.name == Native {
+		return cli.nativeGetNodeInfo(ctx, host, port)
+	}
+
+	args := []string{"-h", host, "-p", strconv.Itoa(port), "info"}
+
+	log.Info().Str("command", string(cli.name)).Strs("args", args).Msg("get node info")
+
+	cmd := cli.command(ctx, args...)
+	resp, err := runCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	info := parseNodeInfo(resp)
+
+	log.Info().Str("host", host).Int("port", port).Msg("finish get node info")
+
+	return info, nil
+}
+
+func (cli *CLI) nativeGetNodeInfo(ctx context.Context, host string, port int) (*NodeInfo, error) {
+	log.Info().Str("command", string(cli.name)).Str("host", host).Int("port", port).Msg("get node info")
+
+	reply, err := cli.do(ctx, host, port, "INFO")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ReplyString(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read info: %w", err)
+	}
+
+	info := parseNodeInfo([]byte(resp))
+
+	log.Info().Str("host", host).Int("port", port).Msg("finish get node info")
+
+	return info, nil
+}
+
+type NodeSnapshot struct {
+	Node  *ClusterNode `json:"node"`
+	Info  *NodeInfo    `json:"info,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+type ClusterSnapshot struct {
+	Time  time.Time      `json:"time"`
+	Nodes []NodeSnapshot `json:"nodes"`
+}
+
+// GetClusterSnapshot collects INFO of every node known to host:port, querying at most parallelism nodes at once.
+// A node that cannot be queried is part of the snapshot with its error.
+func (cli *CLI) GetClusterSnapshot(ctx context.Context, host string, port int, parallelism int) (*ClusterSnapshot, error) {
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	if parallelism <= 0 {
+		parallelism = DefaultSnapshotParallelism
+	}
+
+	snapshot := &ClusterSnapshot{
+		Time:  time.Now(),
+		Nodes: make([]NodeSnapshot, len(nodes)),
+	}
+
+	sem := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+	for i, node := range nodes {
+		snapshot.Nodes[i].Node = node
+
+		nodeHost, nodePort, err := nodeAddress(node, host)
+		if err != nil {
+			snapshot.Nodes[i].Error = err.Error()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				snapshot.Nodes[i].Error = ctx.Err().Error()
+				return
+			}
+			defer func() { <-sem }()
+
+			info, err := cli.GetNodeInfo(ctx, nodeHost, nodePort)
+			if err != nil {
+				snapshot.Nodes[i].Error = err.Error()
+				return
+			}
+			snapshot.Nodes[i].Info = info
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
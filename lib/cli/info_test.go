@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleInfo = `# Server
+redis_version:7.2.4
+server_name:valkey
+valkey_version:8.0.1
+redis_mode:cluster
+server_mode:cluster
+os:Linux 6.1.0 x86_64
+process_id:12
+tcp_port:7001
+uptime_in_seconds:3600
+
+# Clients
+connected_clients:3
+cluster_connections:10
+maxclients:10000
+blocked_clients:1
+
+# Memory
+used_memory:1048576
+used_memory_rss:4194304
+used_memory_peak:2097152
+used_memory_dataset:524288
+maxmemory:0
+maxmemory_policy:noeviction
+mem_fragmentation_ratio:4.00
+
+# Persistence
+loading:0
+rdb_changes_since_last_save:42
+rdb_bgsave_in_progress:0
+rdb_last_save_time:1700000000
+rdb_last_bgsave_status:ok
+aof_enabled:1
+aof_rewrite_in_progress:0
+aof_last_write_status:ok
+
+# Stats
+total_connections_received:100
+total_commands_processed:5000
+instantaneous_ops_per_sec:25
+total_net_input_bytes:123456
+total_net_output_bytes:654321
+instantaneous_input_kbps:1.50
+instantaneous_output_kbps:2.25
+rejected_connections:0
+expired_keys:7
+evicted_keys:0
+keyspace_hits:80
+keyspace_misses:20
+
+# Replication
+role:master
+connected_slaves:2
+slave0:ip=10.0.0.2,port=7002,state=online,offset=1000,lag=0
+slave1:ip=10.0.0.3,port=7003,state=wait_bgsave,offset=0,lag=1
+master_repl_offset:1000
+
+# CPU
+used_cpu_sys:1.25
+used_cpu_user:2.50
+
+# Keyspace
+db1:keys=5,expires=0,avg_ttl=0
+db0:keys=10,expires=2,avg_ttl=3000
+`
+
+func TestParseNodeInfo(t *testing.T) {
+	info := parseNodeInfo([]byte(strings.ReplaceAll(sampleInfo, "\n", "\r\n")))
+
+	if info.Server.Version != "8.0.1" || info.Server.Mode != "cluster" || info.Server.TCPPort != 7001 {
+		t.Errorf("Server = %+v", info.Server)
+	}
+	if info.Clients.ConnectedClients != 3 || info.Clients.BlockedClients != 1 {
+		t.Errorf("Clients = %+v", info.Clients)
+	}
+	if info.Memory.UsedMemory != 1048576 || info.Memory.MaxMemoryPolicy != "noeviction" || info.Memory.MemFragmentationRatio != 4 {
+		t.Errorf("Memory = %+v", info.Memory)
+	}
+	if !info.Persistence.AOFEnabled || info.Persistence.RDBChangesSinceLastSave != 42 || info.Persistence.RDBLastBgsaveStatus != "ok" {
+		t.Errorf("Persistence = %+v", info.Persistence)
+	}
+	if info.Stats.InstantaneousOpsPerSec != 25 || info.Stats.KeyspaceHits != 80 || info.Stats.InstantaneousOutputKbps != 2.25 {
+		t.Errorf("Stats = %+v", info.Stats)
+	}
+	if info.CPU.UsedCPUUser != 2.5 {
+		t.Errorf("CPU = %+v", info.CPU)
+	}
+
+	replication := info.Replication
+	if replication.Role != "master" || len(replication.Replicas) != 2 {
+		t.Fatalf("Replication = %+v", replication)
+	}
+	if r := replication.Replicas[1]; r.IP != "10.0.0.3" || r.Port != 7003 || r.State != "wait_bgsave" || r.Lag != 1 {
+		t.Errorf("Replicas[1] = %+v", r)
+	}
+
+	if len(info.Keyspace) != 2 || info.Keyspace[0].DB != 0 || info.Keyspace[0].Expires != 2 || info.Keyspace[1].Keys != 5 {
+		t.Errorf("Keyspace = %+v", info.Keyspace)
+	}
+	if info.Keys() != 15 {
+		t.Errorf("Keys() = %d, want 15", info.Keys())
+	}
+}
+
+func TestParseNodeInfoReplica(t *testing.T) {
+	info := parseNodeInfo([]byte("# Replication\nrole:slave\nmaster_host:10.0.0.1\nmaster_port:7001\nmaster_link_status:up\nconnected_slaves:0\n"))
+
+	replication := info.Replication
+	if replication.Role != "slave" || replication.MasterHost != "10.0.0.1" || replication.MasterPort != 7001 || replication.MasterLinkStatus != "up" {
+		t.Errorf("Replication = %+v", replication)
+	}
+	if len(info.Keyspace) != 0 || info.Keys() != 0 {
+		t.Errorf("Keyspace = %+v, want empty", info.Keyspace)
+	}
+}
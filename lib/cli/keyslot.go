@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrSlotUnassigned = errors.New("slot is not assigned to any master")
+
+var crc16Table = func() [256]uint16 {
+	table := [256]uint16{}
+	for i := range table {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+// crc16 is the CRC16-CCITT (XMODEM) checksum used by the cluster to hash keys.
+func crc16(data string) uint16 {
+	crc := uint16(0)
+	for i := 0; i < len(data); i++ {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^data[i]]
+	}
+	return crc
+}
+
+// KeySlot returns the hash slot of the key. When the key contains a non-empty {hashtag},
+// only the hashtag is hashed so related keys can be kept in the same slot.
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % MaxSlotCount)
+}
+
+type KeyLocation struct {
+	Key      string         `json:"key"`
+	Slot     int            `json:"slot"`
+	Master   *ClusterNode   `json:"master"`
+	Replicas []*ClusterNode `json:"replicas"`
+}
+
+func (cli *CLI) LocateKey(ctx context.Context, host string, port int, key string) (*KeyLocation, error) {
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	location := &KeyLocation{
+		Key:      key,
+		Slot:     KeySlot(key),
+		Replicas: make([]*ClusterNode, 0),
+	}
+
+	ownerID := NewSlotMap(nodes).Owner(location.Slot)
+	if ownerID == "" {
+		return nil, fmt.Errorf("slot %d of key %q: %w", location.Slot, key, ErrSlotUnassigned)
+	}
+
+	for _, node := range nodes {
+		switch {
+		case node.ID == ownerID:
+			location.Master = node
+		case node.IsReplica() && node.MasterID == ownerID:
+			location.Replicas = append(location.Replicas, node)
+		}
+	}
+
+	return location, nil
+}
@@ -0,0 +1,28 @@
+package cli
+
+import "testing"
+
+func TestKeySlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "123456789", want: 0x31C3},
+		{key: "foo", want: 12182},
+		{key: "bar", want: 5061},
+		{key: "{user1000}.following", want: KeySlot("user1000")},
+		{key: "{user1000}.followers", want: KeySlot("user1000")},
+		{key: "foo{}{bar}", want: int(crc16("foo{}{bar}") % MaxSlotCount)},
+		{key: "foo{{bar}}zap", want: KeySlot("{bar")},
+		{key: "foo{bar}{zap}", want: KeySlot("bar")},
+		{key: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := KeySlot(tt.key); got != tt.want {
+				t.Errorf("KeySlot(%q) = %d, want %d", tt.key, got, tt.want)
+			}
+		})
+	}
+}
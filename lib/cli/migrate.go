@@ -0,0 +1,344 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultMigrateBatch   = 100
+	DefaultMigrateTimeout = 60 * time.Second
+)
+
+var (
+	ErrNodeNotFound = errors.New("node not found")
+	ErrNotMaster    = errors.New("node is not a master")
+)
+
+type MigrationError struct {
+	Slot   int
+	Source string
+	Target string
+	Step   string
+	Err    error
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("failed to %s for slot %d from %s to %s: %v", e.Step, e.Slot, e.Source, e.Target, e.Err)
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+func nodeAddress(node *ClusterNode, fallbackHost string) (string, int, error) {
+	if node.Flags.Has(FlagNoAddr) {
+		return "", 0, fmt.Errorf("node %s has no known address", node.ID)
+	}
+
+	// a node that has not met any other node yet reports an empty address for itself
+	host := node.Host
+	if host == "" {
+		host = fallbackHost
+	}
+
+	return host, node.Port, nil
+}
+
+type migrator struct {
+	cli      *CLI
+	seedHost string
+	nodes    map[string]*ClusterNode
+	conns    map[string]*Conn
+}
+
+func (cli *CLI) newMigrator(ctx context.Context, host string, port int) (*migrator, error) {
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	m := &migrator{
+		cli:      cli,
+		seedHost: host,
+		nodes:    make(map[string]*ClusterNode, len(nodes)),
+		conns:    make(map[string]*Conn),
+	}
+	for _, node := range nodes {
+		m.nodes[node.ID] = node
+	}
+
+	return m, nil
+}
+
+func (m *migrator) close() {
+	for id, conn := range m.conns {
+		if err := conn.Close(); err != nil {
+			log.Error().Err(err).Str("nodeID", id).Msg("failed to close connection")
+		}
+	}
+}
+
+func (m *migrator) master(id string) (*ClusterNode, error) {
+	node, ok := m.nodes[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", id, ErrNodeNotFound)
+	}
+	if !node.IsMaster() {
+		return nil, fmt.Errorf("%s: %w", id, ErrNotMaster)
+	}
+	return node, nil
+}
+
+func (m *migrator) conn(ctx context.Context, node *ClusterNode) (*Conn, error) {
+	if conn, ok := m.conns[node.ID]; ok {
+		return conn, nil
+	}
+
+	host, port, err := nodeAddress(node, m.seedHost)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := m.cli.dial(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+	m.conns[node.ID] = conn
+
+	return conn, nil
+}
+
+func (m *migrator) moveSlot(ctx context.Context, source, target *ClusterNode, slot int) (int, error) {
+	fail := func(step string, err error) (int, error) {
+		return 0, &MigrationError{Slot: slot, Source: source.ID, Target: target.ID, Step: step, Err: err}
+	}
+
+	sourceConn, err := m.conn(ctx, source)
+	if err != nil {
+		return fail("connect to source", err)
+	}
+
+	targetConn, err := m.conn(ctx, target)
+	if err != nil {
+		return fail("connect to target", err)
+	}
+
+	slotStr := strconv.Itoa(slot)
+	if _, err := targetConn.Do(ctx, "CLUSTER", "SETSLOT", slotStr, "IMPORTING", source.ID); err != nil {
+		return fail("set importing", err)
+	}
+
+	if _, err := sourceConn.Do(ctx, "CLUSTER", "SETSLOT", slotStr, "MIGRATING", target.ID); err != nil {
+		return fail("set migrating", err)
+	}
+
+	moved, err := m.drainKeys(ctx, source, target, slot)
+	if err != nil {
+		return moved, err
+	}
+
+	if err := m.setSlotOwner(ctx, slot, target, source); err != nil {
+		return moved, err
+	}
+
+	return moved, nil
+}
+
+// drainKeys moves every key of the slot that is stored on source to target.
+func (m *migrator) drainKeys(ctx context.Context, source, target *ClusterNode, slot int) (int, error) {
+	fail := func(moved int, step string, err error) (int, error) {
+		return moved, &MigrationError{Slot: slot, Source: source.ID, Target: target.ID, Step: step, Err: err}
+	}
+
+	sourceConn, err := m.conn(ctx, source)
+	if err != nil {
+		return fail(0, "connect to source", err)
+	}
+
+	targetHost, targetPort, err := nodeAddress(target, m.seedHost)
+	if err != nil {
+		return fail(0, "resolve target address", err)
+	}
+
+	slotStr := strconv.Itoa(slot)
+	moved := 0
+	for {
+		reply, err := sourceConn.Do(ctx, "CLUSTER", "GETKEYSINSLOT", slotStr, strconv.Itoa(DefaultMigrateBatch))
+		if err != nil {
+			return fail(moved, "get keys in slot", err)
+		}
+
+		keys, err := ReplyStrings(reply)
+		if err != nil {
+			return fail(moved, "get keys in slot", err)
+		}
+
+		if len(keys) == 0 {
+			break
+		}
+
+		args := []string{"MIGRATE", targetHost, strconv.Itoa(targetPort), "", "0", strconv.FormatInt(DefaultMigrateTimeout.Milliseconds(), 10)}
+		switch {
+		case m.cli.user != "" && m.cli.password != "":
+			args = append(args, "AUTH2", m.cli.user, m.cli.password)
+		case m.cli.password != "":
+			args = append(args, "AUTH", m.cli.password)
+		}
+		args = append(args, "KEYS")
+		args = append(args, keys...)
+
+		if _, err := sourceConn.Do(ctx, args...); err != nil {
+			return fail(moved, "migrate keys", err)
+		}
+
+		moved += len(keys)
+	}
+
+	return moved, nil
+}
+
+// setSlotOwner assigns the slot to owner, first on the owner itself, then on previous and finally on every other master.
+func (m *migrator) setSlotOwner(ctx context.Context, slot int, owner *ClusterNode, previous *ClusterNode) error {
+	slotStr := strconv.Itoa(slot)
+
+	ownerConn, err := m.conn(ctx, owner)
+	if err != nil {
+		return &MigrationError{Slot: slot, Source: previous.ID, Target: owner.ID, Step: "connect to target", Err: err}
+	}
+
+	if _, err := ownerConn.Do(ctx, "CLUSTER", "SETSLOT", slotStr, "NODE", owner.ID); err != nil {
+		return &MigrationError{Slot: slot, Source: previous.ID, Target: owner.ID, Step: "set slot node on target", Err: err}
+	}
+
+	previousConn, err := m.conn(ctx, previous)
+	if err != nil {
+		return &MigrationError{Slot: slot, Source: previous.ID, Target: owner.ID, Step: "connect to source", Err: err}
+	}
+
+	if _, err := previousConn.Do(ctx, "CLUSTER", "SETSLOT", slotStr, "NODE", owner.ID); err != nil {
+		return &MigrationError{Slot: slot, Source: previous.ID, Target: owner.ID, Step: "set slot node on source", Err: err}
+	}
+
+	for _, node := range m.nodes {
+		if node.ID == previous.ID || node.ID == owner.ID || !node.IsMaster() {
+			continue
+		}
+
+		conn, err := m.conn(ctx, node)
+		if err != nil {
+			log.Warn().Err(err).Str("nodeID", node.ID).Int("slot", slot).Msg("failed to inform node about slot owner")
+			continue
+		}
+
+		if _, err := conn.Do(ctx, "CLUSTER", "SETSLOT", slotStr, "NODE", owner.ID); err != nil {
+			log.Warn().Err(err).Str("nodeID", node.ID).Int("slot", slot).Msg("failed to inform node about slot owner")
+		}
+	}
+
+	return nil
+}
+
+// MigrateSlots moves the given slot ranges from sourceID to targetID one slot at a time with
+// CLUSTER SETSLOT and MIGRATE. The host and port are used to discover the cluster topology.
+func (cli *CLI) MigrateSlots(ctx context.Context, host string, port int, sourceID string, targetID string, slots SlotSet) error {
+	m, err := cli.newMigrator(ctx, host, port)
+	if err != nil {
+		return err
+	}
+	defer m.close()
+
+	return m.migrate(ctx, sourceID, targetID, slots)
+}
+
+func (m *migrator) migrate(ctx context.Context, sourceID string, targetID string, slots SlotSet) error {
+	source, err := m.master(sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to find source node: %w", err)
+	}
+
+	target, err := m.master(targetID)
+	if err != nil {
+		return fmt.Errorf("failed to find target node: %w", err)
+	}
+
+	slots = NewSlotSet(slots...)
+	total := slots.Count()
+
+	log.Info().Str("source", sourceID).Str("target", targetID).Int("slots", total).Msg("migrate slots")
+
+	done := 0
+	for _, r := range slots {
+		for slot := r.Start; slot <= r.End; slot++ {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("migration interrupted after %d of %d slots: %w", done, total, err)
+			}
+
+			keys, err := m.moveSlot(ctx, source, target, slot)
+			if err != nil {
+				return err
+			}
+
+			done++
+			ReportProgress(ctx, Progress{
+				Operation: "migrate",
+				Slot:      slot,
+				Source:    sourceID,
+				Target:    targetID,
+				Keys:      keys,
+				Done:      done,
+				Total:     total,
+			})
+		}
+	}
+
+	log.Info().Str("source", sourceID).Str("target", targetID).Int("slots", total).Msg("finish migrate slots")
+
+	return nil
+}
+
+// pickSlots chooses count slots among the sources, taking from each source in proportion to the number of slots it owns.
+func pickSlots(sources []*ClusterNode, count int) map[string]SlotSet {
+	owned := 0
+	for _, source := range sources {
+		owned += source.Slots.Count()
+	}
+
+	picked := make(map[string]SlotSet)
+	if owned == 0 || count <= 0 {
+		return picked
+	}
+	if count > owned {
+		count = owned
+	}
+
+	quotas := make([]int, len(sources))
+	remaining := count
+	for i, source := range sources {
+		quotas[i] = min(count*source.Slots.Count()/owned, remaining)
+		remaining -= quotas[i]
+	}
+	// the floored shares fall short of count, make it up one slot at a time from the sources with slots left
+	for i := 0; remaining > 0; i = (i + 1) % len(sources) {
+		if quotas[i] < sources[i].Slots.Count() {
+			quotas[i]++
+			remaining--
+		}
+	}
+
+	for i, source := range sources {
+		if quotas[i] == 0 {
+			continue
+		}
+
+		// take from the tail of the owned ranges so the source keeps a contiguous head
+		picked[source.ID] = source.Slots.Tail(quotas[i])
+	}
+
+	return picked
+}
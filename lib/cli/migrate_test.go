@@ -0,0 +1,44 @@
+package cli
+
+import "testing"
+
+func TestPickSlots(t *testing.T) {
+	sources := []*ClusterNode{
+		{ID: "a", Slots: SlotSet{{Start: 0, End: 4}}},
+		{ID: "b", Slots: SlotSet{{Start: 5, End: 9}}},
+		{ID: "c", Slots: SlotSet{{Start: 10, End: 10}}},
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		want  map[string]string
+	}{
+		{"proportional", 11, map[string]string{"a": "0-4", "b": "5-9", "c": "10"}},
+		{"shortfall of the last source", 10, map[string]string{"a": "0-4", "b": "5-9"}},
+		{"floored shares", 4, map[string]string{"a": "3-4", "b": "8-9"}},
+		{"single source short", 7, map[string]string{"a": "1-4", "b": "7-9"}},
+		{"more than owned", 20, map[string]string{"a": "0-4", "b": "5-9", "c": "10"}},
+		{"nothing", 0, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picked := pickSlots(sources, tt.count)
+
+			total := 0
+			for id, slots := range picked {
+				total += slots.Count()
+				if slots.String() != tt.want[id] {
+					t.Errorf("pickSlots()[%s] = %s, want %s", id, slots, tt.want[id])
+				}
+			}
+			if len(picked) != len(tt.want) {
+				t.Errorf("pickSlots() = %v, want %v", picked, tt.want)
+			}
+			if want := min(tt.count, 11); total != want {
+				t.Errorf("pickSlots() picked %d slots, want %d", total, want)
+			}
+		})
+	}
+}
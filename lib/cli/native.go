@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrNativeUnsupported = errors.New("operation is not supported by the native backend")
+
+func (cli *CLI) dial(ctx context.Context, host string, port int) (*Conn, error) {
+	opts, err := cli.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+	return DialWith(ctx, host, port, opts)
+}
+
+func (cli *CLI) do(ctx context.Context, host string, port int, args ...string) (any, error) {
+	conn, err := cli.dial(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %v on %s:%d: %w", args, host, port, err)
+	}
+
+	return reply, nil
+}
+
+func (cli *CLI) nativeGetClusterNodes(ctx context.Context, host string, port int) ([]*ClusterNode, error) {
+	log.Info().Str("command", string(cli.name)).Str("host", host).Int("port", port).Msg("get cluster nodes")
+
+	reply, err := cli.do(ctx, host, port, "CLUSTER", "NODES")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ReplyString(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster nodes: %w", err)
+	}
+
+	nodes, err := ParseClusterNodes([]byte(resp))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cluster nodes: %w", err)
+	}
+
+	log.Info().Interface("nodes", nodes).Msg("finish get cluster nodes")
+
+	return nodes, nil
+}
+
+func (cli *CLI) nativeGetClusterInfo(ctx context.Context, host string, port int) (*ClusterInfo, error) {
+	log.Info().Str("command", string(cli.name)).Str("host", host).Int("port", port).Msg("get cluster info")
+
+	reply, err := cli.do(ctx, host, port, "CLUSTER", "INFO")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ReplyString(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster info: %w", err)
+	}
+
+	info := parseClusterInfo([]byte(resp))
+
+	log.Info().Interface("info", info).Msg("finish get cluster info")
+
+	return info, nil
+}
+
+func (cli *CLI) nativeForgetNode(ctx context.Context, host string, port int, nodeID string) error {
+	log.Info().Str("command", string(cli.name)).Str("host", host).Int("port", port).Str("nodeID", nodeID).Msg("forget node")
+
+	if _, err := cli.do(ctx, host, port, "CLUSTER", "FORGET", nodeID); err != nil {
+		return err
+	}
+
+	log.Info().Msg("finish forget node")
+
+	return nil
+}
+
+func (cli *CLI) nativeReplicateNode(ctx context.Context, host string, port int, masterNodeID string) error {
+	log.Info().Str("command", string(cli.name)).Str("host", host).Int("port", port).Str("masterNodeID", masterNodeID).Msg("replicate node")
+
+	if _, err := cli.do(ctx, host, port, "CLUSTER", "REPLICATE", masterNodeID); err != nil {
+		return err
+	}
+
+	log.Info().Msg("finish replicate node")
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type NodeFlag string
+
+const (
+	FlagMyself     NodeFlag = "myself"
+	FlagMaster     NodeFlag = "master"
+	FlagSlave      NodeFlag = "slave"
+	FlagPFail      NodeFlag = "fail?"
+	FlagFail       NodeFlag = "fail"
+	FlagHandshake  NodeFlag = "handshake"
+	FlagNoAddr     NodeFlag = "noaddr"
+	FlagNoFailover NodeFlag = "nofailover"
+	FlagNoFlags    NodeFlag = "noflags"
+)
+
+type NodeFlags map[NodeFlag]struct{}
+
+func NewNodeFlags(flags ...NodeFlag) NodeFlags {
+	set := make(NodeFlags, len(flags))
+	for _, flag := range flags {
+		set[flag] = struct{}{}
+	}
+	return set
+}
+
+func (f NodeFlags) Has(flag NodeFlag) bool {
+	_, ok := f[flag]
+	return ok
+}
+
+func (f NodeFlags) List() []NodeFlag {
+	list := make([]NodeFlag, 0, len(f))
+	for flag := range f {
+		list = append(list, flag)
+	}
+	slices.Sort(list)
+	return list
+}
+
+func (f NodeFlags) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.List())
+}
+
+func (f *NodeFlags) UnmarshalJSON(data []byte) error {
+	list := make([]NodeFlag, 0)
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*f = NewNodeFlags(list...)
+	return nil
+}
+
+type ClusterNode struct {
+	ID           string         `json:"id"`
+	Host         string         `json:"host"`
+	Port         int            `json:"port"`
+	ClusterPort  int            `json:"cluster_port"`
+	Hostname     string         `json:"hostname,omitempty"`
+	Flags        NodeFlags      `json:"flags"`
+	MasterID     string         `json:"master_id,omitempty"`
+	PingSent     int64          `json:"ping_sent"`
+	PongReceived int64          `json:"pong_received"`
+	ConfigEpoch  int64          `json:"config_epoch"`
+	LinkState    string         `json:"link_state"`
+	Slots        SlotSet        `json:"slots,omitempty"`
+	Importing    map[int]string `json:"importing,omitempty"`
+	Migrating    map[int]string `json:"migrating,omitempty"`
+}
+
+func (n *ClusterNode) IsMaster() bool {
+	return n.Flags.Has(FlagMaster)
+}
+
+func (n *ClusterNode) IsReplica() bool {
+	return n.Flags.Has(FlagSlave)
+}
+
+func (n *ClusterNode) IsMyself() bool {
+	return n.Flags.Has(FlagMyself)
+}
+
+func (n *ClusterNode) IsFailing() bool {
+	return n.Flags.Has(FlagFail) || n.Flags.Has(FlagPFail)
+}
+
+func (n *ClusterNode) IsConnected() bool {
+	return n.LinkState == "connected"
+}
+
+func (n *ClusterNode) Address() string {
+	return n.Host + ":" + strconv.Itoa(n.Port)
+}
+
+// ParseClusterNodes parses the output of CLUSTER NODES (or a nodes.conf file) into one ClusterNode per line.
+func ParseClusterNodes(data []byte) ([]*ClusterNode, error) {
+	nodes := make([]*ClusterNode, 0)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "txt:"))
+		if line == "" || strings.HasPrefix(line, "vars ") {
+			continue
+		}
+
+		node, err := ParseClusterNode(line)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cluster nodes: %w", err)
+	}
+
+	return nodes, nil
+}
+
+// ParseClusterNode parses a single line of CLUSTER NODES:
+// <id> <ip:port@cport[,hostname[,aux=value...]]> <flags> <master> <ping-sent> <pong-recv> <config-epoch> <link-state> <slot> ... <slot>
+func ParseClusterNode(line string) (*ClusterNode, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 8 {
+		return nil, fmt.Errorf("malformed cluster node %q: expected at least 8 fields, got %d", line, len(fields))
+	}
+
+	node := &ClusterNode{
+		ID:        fields[0],
+		Flags:     make(NodeFlags),
+		LinkState: fields[7],
+	}
+
+	if err := parseNodeAddress(node, fields[1]); err != nil {
+		return nil, fmt.Errorf("malformed address of node %s: %w", node.ID, err)
+	}
+
+	for _, flag := range strings.Split(fields[2], ",") {
+		node.Flags[NodeFlag(flag)] = struct{}{}
+	}
+
+	if fields[3] != "-" {
+		node.MasterID = fields[3]
+	}
+
+	var err error
+	if node.PingSent, err = strconv.ParseInt(fields[4], 10, 64); err != nil {
+		return nil, fmt.Errorf("malformed ping sent of node %s: %w", node.ID, err)
+	}
+	if node.PongReceived, err = strconv.ParseInt(fields[5], 10, 64); err != nil {
+		return nil, fmt.Errorf("malformed pong received of node %s: %w", node.ID, err)
+	}
+	if node.ConfigEpoch, err = strconv.ParseInt(fields[6], 10, 64); err != nil {
+		return nil, fmt.Errorf("malformed config epoch of node %s: %w", node.ID, err)
+	}
+
+	for _, field := range fields[8:] {
+		if err := parseNodeSlot(node, field); err != nil {
+			return nil, fmt.Errorf("malformed slot %q of node %s: %w", field, node.ID, err)
+		}
+	}
+	node.Slots = NewSlotSet(node.Slots...)
+
+	return node, nil
+}
+
+func parseNodeAddress(node *ClusterNode, field string) error {
+	address, extra, _ := strings.Cut(field, ",")
+	if extra != "" {
+		// the hostname comes first, nodes.conf appends aux fields such as shard-id=... after it
+		hostname, _, _ := strings.Cut(extra, ",")
+		if !strings.Contains(hostname, "=") {
+			node.Hostname = hostname
+		}
+	}
+
+	address, cport, hasCport := strings.Cut(address, "@")
+	if hasCport {
+		port, err := strconv.Atoi(cport)
+		if err != nil {
+			return fmt.Errorf("invalid cluster bus port %q: %w", cport, err)
+		}
+		node.ClusterPort = port
+	}
+
+	idx := strings.LastIndexByte(address, ':')
+	if idx < 0 {
+		return fmt.Errorf("missing port in %q", address)
+	}
+
+	port, err := strconv.Atoi(address[idx+1:])
+	if err != nil {
+		return fmt.Errorf("invalid port %q: %w", address[idx+1:], err)
+	}
+
+	node.Host = strings.TrimSuffix(strings.TrimPrefix(address[:idx], "["), "]")
+	node.Port = port
+	if !hasCport {
+		node.ClusterPort = port + 10000
+	}
+
+	return nil
+}
+
+func parseNodeSlot(node *ClusterNode, field string) error {
+	if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+		marker := field[1 : len(field)-1]
+		if slot, peer, ok := strings.Cut(marker, "->-"); ok {
+			n, err := strconv.Atoi(slot)
+			if err != nil {
+				return err
+			}
+			if node.Migrating == nil {
+				node.Migrating = make(map[int]string)
+			}
+			node.Migrating[n] = peer
+			return nil
+		}
+		if slot, peer, ok := strings.Cut(marker, "-<-"); ok {
+			n, err := strconv.Atoi(slot)
+			if err != nil {
+				return err
+			}
+			if node.Importing == nil {
+				node.Importing = make(map[int]string)
+			}
+			node.Importing[n] = peer
+			return nil
+		}
+		return fmt.Errorf("unknown slot marker")
+	}
+
+	start, end, isRange := strings.Cut(field, "-")
+	first, err := strconv.Atoi(start)
+	if err != nil {
+		return err
+	}
+	last := first
+	if isRange {
+		if last, err = strconv.Atoi(end); err != nil {
+			return err
+		}
+	}
+
+	if first < 0 || last >= MaxSlotCount || first > last {
+		return fmt.Errorf("slot range out of bounds")
+	}
+
+	node.Slots = append(node.Slots, SlotRange{Start: first, End: last})
+
+	return nil
+}
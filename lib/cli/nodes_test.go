@@ -0,0 +1,411 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseClusterNodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    []*ClusterNode
+	}{
+		{
+			name:    "valkey 7 with open slot",
+			fixture: "cluster_nodes_valkey7.txt",
+			want: []*ClusterNode{
+				{
+					ID:           "b6589fc6ab0dc82cf12099d1c2d40ab994e8410c",
+					Host:         "10.0.0.1",
+					Port:         6379,
+					ClusterPort:  16379,
+					Flags:        NewNodeFlags(FlagMyself, FlagMaster),
+					PongReceived: 1717000000000,
+					ConfigEpoch:  1,
+					LinkState:    "connected",
+					Slots:        []SlotRange{{Start: 0, End: 5460}},
+					Importing:    map[int]string{5461: "356a192b7913b04c54574d18c28d46e6395428ab"},
+				},
+				{
+					ID:           "356a192b7913b04c54574d18c28d46e6395428ab",
+					Host:         "10.0.0.2",
+					Port:         6379,
+					ClusterPort:  16379,
+					Flags:        NewNodeFlags(FlagMaster),
+					PongReceived: 1717000001000,
+					ConfigEpoch:  2,
+					LinkState:    "connected",
+					Slots:        []SlotRange{{Start: 5461, End: 10922}},
+					Migrating:    map[int]string{5461: "b6589fc6ab0dc82cf12099d1c2d40ab994e8410c"},
+				},
+				{
+					ID:           "da4b9237bacccdf19c0760cab7aec4a8359010b0",
+					Host:         "10.0.0.3",
+					Port:         6379,
+					ClusterPort:  16379,
+					Flags:        NewNodeFlags(FlagMaster),
+					PongReceived: 1717000002000,
+					ConfigEpoch:  3,
+					LinkState:    "connected",
+					Slots:        []SlotRange{{Start: 10923, End: 16383}},
+				},
+				{
+					ID:           "77de68daecd823babbb58edb1c8e14d7106e83bb",
+					Host:         "10.0.0.2",
+					Port:         6380,
+					ClusterPort:  16380,
+					Flags:        NewNodeFlags(FlagSlave),
+					MasterID:     "b6589fc6ab0dc82cf12099d1c2d40ab994e8410c",
+					PongReceived: 1717000003000,
+					ConfigEpoch:  1,
+					LinkState:    "connected",
+				},
+				{
+					ID:           "1b6453892473a467d07372d45eb05abc2031647a",
+					Host:         "10.0.0.3",
+					Port:         6380,
+					ClusterPort:  16380,
+					Flags:        NewNodeFlags(FlagSlave),
+					MasterID:     "356a192b7913b04c54574d18c28d46e6395428ab",
+					PongReceived: 1717000004000,
+					ConfigEpoch:  2,
+					LinkState:    "connected",
+				},
+				{
+					ID:           "ac3478d69a3c81fa62e60f5c3696165a4e5e6ac4",
+					Host:         "10.0.0.1",
+					Port:         6380,
+					ClusterPort:  16380,
+					Flags:        NewNodeFlags(FlagSlave),
+					MasterID:     "da4b9237bacccdf19c0760cab7aec4a8359010b0",
+					PongReceived: 1717000005000,
+					ConfigEpoch:  3,
+					LinkState:    "connected",
+				},
+			},
+		},
+		{
+			name:    "valkey 8 with hostnames and failures",
+			fixture: "cluster_nodes_valkey8.txt",
+			want: []*ClusterNode{
+				{
+					ID:          "c1dfd96eea8cc2b62785275bca38ac261256e278",
+					Host:        "172.18.0.2",
+					Port:        7001,
+					ClusterPort: 17001,
+					Hostname:    "valkey-1",
+					Flags:       NewNodeFlags(FlagMyself, FlagMaster),
+					ConfigEpoch: 7,
+					LinkState:   "connected",
+					Slots:       []SlotRange{{Start: 0, End: 5460}, {Start: 10923, End: 10923}, {Start: 12000, End: 12010}},
+				},
+				{
+					ID:           "902ba3cda1883801594b6e1b452790cc53948fda",
+					Host:         "172.18.0.3",
+					Port:         7002,
+					ClusterPort:  17002,
+					Hostname:     "valkey-2",
+					Flags:        NewNodeFlags(FlagMaster),
+					PongReceived: 1731900000123,
+					ConfigEpoch:  8,
+					LinkState:    "connected",
+					Slots:        []SlotRange{{Start: 5461, End: 10922}},
+				},
+				{
+					ID:           "fe5dbbcea5ce7e2988b8c69bcfdfde8904aabc1f",
+					Host:         "172.18.0.4",
+					Port:         7003,
+					ClusterPort:  17003,
+					Hostname:     "valkey-3",
+					Flags:        NewNodeFlags(FlagMaster, FlagFail),
+					PingSent:     1731899990000,
+					PongReceived: 1731899980000,
+					ConfigEpoch:  9,
+					LinkState:    "disconnected",
+					Slots:        []SlotRange{{Start: 10924, End: 11999}, {Start: 12011, End: 16383}},
+				},
+				{
+					ID:           "0ade7c2cf97f75d009975f4d720d1fa6c19f4897",
+					Host:         "172.18.0.5",
+					Port:         7004,
+					ClusterPort:  17004,
+					Hostname:     "valkey-4",
+					Flags:        NewNodeFlags(FlagSlave, FlagPFail),
+					MasterID:     "c1dfd96eea8cc2b62785275bca38ac261256e278",
+					PingSent:     1731900000500,
+					PongReceived: 1731899999000,
+					ConfigEpoch:  7,
+					LinkState:    "connected",
+				},
+				{
+					ID:           "b1d5781111d84f7b3fe45a0852e59758cd7a87e5",
+					Host:         "172.18.0.6",
+					Port:         7005,
+					ClusterPort:  17005,
+					Hostname:     "valkey-5",
+					Flags:        NewNodeFlags(FlagSlave, FlagNoFailover),
+					MasterID:     "902ba3cda1883801594b6e1b452790cc53948fda",
+					PongReceived: 1731900001000,
+					ConfigEpoch:  8,
+					LinkState:    "connected",
+				},
+				{
+					ID:           "17ba0791499db908433b80f37c5fbc89b870084b",
+					Flags:        NewNodeFlags(FlagMaster, FlagNoAddr),
+					PingSent:     1731900002000,
+					PongReceived: 1731900002000,
+					LinkState:    "disconnected",
+				},
+			},
+		},
+		{
+			name:    "redis 7 with hostnames",
+			fixture: "cluster_nodes_redis7.txt",
+			want: []*ClusterNode{
+				{
+					ID:           "07c37dfeb235213a872192d90877d0cd55635b91",
+					Host:         "127.0.0.1",
+					Port:         30004,
+					ClusterPort:  31004,
+					Hostname:     "hostname4",
+					Flags:        NewNodeFlags(FlagSlave),
+					MasterID:     "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca",
+					PongReceived: 1426238317239,
+					ConfigEpoch:  4,
+					LinkState:    "connected",
+				},
+				{
+					ID:           "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1",
+					Host:         "127.0.0.1",
+					Port:         30002,
+					ClusterPort:  31002,
+					Hostname:     "hostname2",
+					Flags:        NewNodeFlags(FlagMaster),
+					PongReceived: 1426238316232,
+					ConfigEpoch:  2,
+					LinkState:    "connected",
+					Slots:        []SlotRange{{Start: 5461, End: 10922}},
+				},
+				{
+					ID:           "292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f",
+					Host:         "127.0.0.1",
+					Port:         30003,
+					ClusterPort:  31003,
+					Hostname:     "hostname3",
+					Flags:        NewNodeFlags(FlagMaster),
+					PongReceived: 1426238318243,
+					ConfigEpoch:  3,
+					LinkState:    "connected",
+					Slots:        []SlotRange{{Start: 10923, End: 16383}},
+				},
+				{
+					ID:           "6ec23923021cf3ffec47632106199cb7f496ce01",
+					Host:         "127.0.0.1",
+					Port:         30005,
+					ClusterPort:  31005,
+					Hostname:     "hostname5",
+					Flags:        NewNodeFlags(FlagSlave),
+					MasterID:     "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1",
+					PongReceived: 1426238316232,
+					ConfigEpoch:  5,
+					LinkState:    "connected",
+				},
+				{
+					ID:           "824fe116063bc5fcf9f4ffd895bc17aee7731ac3",
+					Host:         "127.0.0.1",
+					Port:         30006,
+					ClusterPort:  31006,
+					Hostname:     "hostname6",
+					Flags:        NewNodeFlags(FlagSlave),
+					MasterID:     "292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f",
+					PongReceived: 1426238317741,
+					ConfigEpoch:  6,
+					LinkState:    "connected",
+				},
+				{
+					ID:          "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca",
+					Host:        "127.0.0.1",
+					Port:        30001,
+					ClusterPort: 31001,
+					Hostname:    "hostname1",
+					Flags:       NewNodeFlags(FlagMyself, FlagMaster),
+					ConfigEpoch: 1,
+					LinkState:   "connected",
+					Slots:       []SlotRange{{Start: 0, End: 5460}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			got, err := ParseClusterNodes(data)
+			if err != nil {
+				t.Fatalf("ParseClusterNodes() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseClusterNodes() returned %d nodes, want %d", len(got), len(tt.want))
+			}
+
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("node %d:\n got = %+v\nwant = %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseClusterNode(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    *ClusterNode
+		wantErr bool
+	}{
+		{
+			name: "nodes.conf aux fields without hostname",
+			line: "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001,,shard-id=9d8b3c1b0f4e6a2d5c7b8a9e0f1d2c3b4a5e6f70 myself,master - 0 0 1 connected 0-5460",
+			want: &ClusterNode{
+				ID:          "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca",
+				Host:        "127.0.0.1",
+				Port:        30001,
+				ClusterPort: 31001,
+				Flags:       NewNodeFlags(FlagMyself, FlagMaster),
+				ConfigEpoch: 1,
+				LinkState:   "connected",
+				Slots:       []SlotRange{{Start: 0, End: 5460}},
+			},
+		},
+		{
+			name: "ipv6 address",
+			line: "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca ::1:30001@31001 master - 0 0 1 connected",
+			want: &ClusterNode{
+				ID:          "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca",
+				Host:        "::1",
+				Port:        30001,
+				ClusterPort: 31001,
+				Flags:       NewNodeFlags(FlagMaster),
+				ConfigEpoch: 1,
+				LinkState:   "connected",
+			},
+		},
+		{
+			name: "legacy address without bus port",
+			line: "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:6379 handshake - 0 0 0 connected",
+			want: &ClusterNode{
+				ID:          "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca",
+				Host:        "127.0.0.1",
+				Port:        6379,
+				ClusterPort: 16379,
+				Flags:       NewNodeFlags(FlagHandshake),
+				LinkState:   "connected",
+			},
+		},
+		{
+			name:    "too few fields",
+			line:    "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:6379@16379 master - 0 0",
+			wantErr: true,
+		},
+		{
+			name:    "slot out of range",
+			line:    "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:6379@16379 master - 0 0 1 connected 0-16384",
+			wantErr: true,
+		},
+		{
+			name:    "unknown slot marker",
+			line:    "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:6379@16379 master - 0 0 1 connected [5461]",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseClusterNode(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseClusterNode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseClusterNode():\n got = %+v\nwant = %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseFixtures checks every fixture written by testdata/capture.sh, so a capture of a new server version is
+// parsed without spelling out each node.
+func TestParseFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			nodes, err := ParseClusterNodes(data)
+			if err != nil {
+				t.Fatalf("ParseClusterNodes() error = %v", err)
+			}
+			if len(nodes) == 0 {
+				t.Fatal("ParseClusterNodes() returned no nodes")
+			}
+
+			ids := make(map[string]bool, len(nodes))
+			myself := 0
+			for _, node := range nodes {
+				if len(node.ID) != 40 {
+					t.Errorf("node %q has no 40 character ID", node.ID)
+				}
+				ids[node.ID] = true
+				if node.IsMyself() {
+					myself++
+				}
+			}
+			if myself != 1 {
+				t.Errorf("%d nodes flagged myself, want 1", myself)
+			}
+
+			for _, node := range nodes {
+				if node.Port <= 0 && !node.Flags.Has(FlagNoAddr) {
+					t.Errorf("node %s has no port", node.ID)
+				}
+				if node.IsReplica() && !ids[node.MasterID] {
+					t.Errorf("replica %s follows unknown master %q", node.ID, node.MasterID)
+				}
+				if !node.IsMaster() && !node.Slots.IsEmpty() {
+					t.Errorf("non master %s owns slots %s", node.ID, node.Slots)
+				}
+				for _, r := range node.Slots {
+					if r.Start < 0 || r.End >= MaxSlotCount || r.Start > r.End {
+						t.Errorf("node %s owns invalid slot range %v", node.ID, r)
+					}
+				}
+				for slot, id := range node.Migrating {
+					if !ids[id] {
+						t.Errorf("node %s migrates slot %d to unknown node %q", node.ID, slot, id)
+					}
+				}
+				for slot, id := range node.Importing {
+					if !ids[id] {
+						t.Errorf("node %s imports slot %d from unknown node %q", node.ID, slot, id)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import "context"
+
+// ClusterOperator is the set of cluster operations keycl depends on.
+// CLI implements it for every CliName, other implementations (e.g. fakes in tests) can be swapped in.
+type ClusterOperator interface {
+	CreateCluster(ctx context.Context, replicas int, address ...string) error
+	AddNode(ctx context.Context, newNodeHost string, newNodePort int, existingNodeHost string, existingNodePort int) error
+	Reshard(ctx context.Context, host string, port int, targetNode string, slots int, sourceNode string) error
+	ReshardAll(ctx context.Context, host string, port int) error
+	ForgetNode(ctx context.Context, host string, port int, nodeID string) error
+	DeleteNode(ctx context.Context, host string, port int, nodeID string) error
+	ReplicateNode(ctx context.Context, host string, port int, masterNodeID string) error
+	Failover(ctx context.Context, host string, port int, opts FailoverOptions) error
+	ApplyReplicaPlan(ctx context.Context, plan *ReplicaPlan) error
+	Rebalance(ctx context.Context, host string, port int) error
+	RebalanceWith(ctx context.Context, host string, port int, opts RebalanceOptions) (*RebalancePlan, error)
+	ExecuteRebalance(ctx context.Context, host string, port int, plan *RebalancePlan) error
+	DecommissionNode(ctx context.Context, host string, port int, nodeID string, opts DecommissionOptions) (*DecommissionResult, error)
+	ExceptNode(ctx context.Context, host string, port int, exceptionNode string) error
+	MergeNode(ctx context.Context, host string, port int, targetNodeID string, sourceNodeID string) error
+	MigrateSlots(ctx context.Context, host string, port int, sourceID string, targetID string, slots SlotSet) error
+	FixCluster(ctx context.Context, host string, port int, opts FixOptions) ([]FixAction, error)
+	Reconcile(ctx context.Context, host string, port int, spec *ClusterSpec, opts ReconcileOptions) (*ReconcilePlan, error)
+
+	GetClusterNodes(ctx context.Context, host string, port int) ([]*ClusterNode, error)
+	GetNoSlotNodes(ctx context.Context, host string, port int) ([]string, int, error)
+	GetClusterInfo(ctx context.Context, host string, port int) (*ClusterInfo, error)
+	GetNodeInfo(ctx context.Context, host string, port int) (*NodeInfo, error)
+	GetClusterSnapshot(ctx context.Context, host string, port int, parallelism int) (*ClusterSnapshot, error)
+	GetSlotMap(ctx context.Context, host string, port int) (*SlotMap, error)
+	LocateKey(ctx context.Context, host string, port int, key string) (*KeyLocation, error)
+	CheckCluster(ctx context.Context, host string, port int) ([]Finding, error)
+	MeasureSlots(ctx context.Context, host string, port int, samples int) ([]SlotUsage, error)
+	ScanKeyspace(ctx context.Context, host string, port int, opts ScanOptions) (*KeyspaceReport, error)
+}
+
+var _ ClusterOperator = (*CLI)(nil)
+
+// OperatorFactory builds a ClusterOperator for the named cluster protected by the given password.
+type OperatorFactory func(clusterName string, password string) ClusterOperator
+
+func NewOperatorFactory(name CliName, opts ...Option) OperatorFactory {
+	return func(clusterName string, password string) ClusterOperator {
+		return New(name, password, opts...)
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import "context"
+
+type Progress struct {
+	Operation string `json:"operation"`
+	Slot      int    `json:"slot"`
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	Keys      int    `json:"keys"`
+	Done      int    `json:"done"`
+	Total     int    `json:"total"`
+	Message   string `json:"message,omitempty"`
+}
+
+type ProgressFunc func(Progress)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose long-running operations report their progress to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress sends progress to the function set by WithProgress, also for operators implemented outside this package.
+func ReportProgress(ctx context.Context, progress Progress) {
+	if fn := progressFunc(ctx); fn != nil {
+		fn(progress)
+	}
+}
+
+func progressFunc(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
@@ -2,33 +2,49 @@ package cli
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 type Reactor struct {
-	reaction map[string]string
-	reader   io.Reader
-	writer   io.Writer
+	reaction    map[string]string
+	reader      io.Reader
+	writer      io.Writer
+	idleTimeout time.Duration
+	progress    *progressParser
+	line        []byte
+	errorLines  []string
 }
 
 func NewReactor(reader io.Reader, writer io.Writer) *Reactor {
 	return &Reactor{
-		reaction: make(map[string]string),
-		reader:   reader,
-		writer:   writer,
+		reaction:    make(map[string]string),
+		reader:      reader,
+		writer:      writer,
+		idleTimeout: DefaultIdleTimeout,
 	}
 }
 
+func (r *Reactor) SetIdleTimeout(timeout time.Duration) {
+	r.idleTimeout = timeout
+}
+
 func (r *Reactor) AddReaction(command, response string) {
 	r.reaction[command] = response
 }
 
+// OnProgress makes React report the slot moves and steps printed by the command to fn.
+func (r *Reactor) OnProgress(operation string, fn ProgressFunc) {
+	r.progress = newProgressParser(operation, fn)
+}
+
 func SplitRedisCommand(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	if atEOF && len(data) == 0 {
 		return 0, nil, nil
@@ -44,8 +60,9 @@ loop:
 		}
 	}
 
+	// the delimiter stays in the token so the tokens concatenate back into the original output
 	if idx >= 0 {
-		token := data[0:idx]
+		token := data[0 : idx+1]
 		advance = idx + 1
 		return advance, token, nil
 	}
@@ -61,45 +78,230 @@ loop:
 	return 0, nil, nil
 }
 
-func (r *Reactor) React(cancel func()) {
+// React answers the prompts of the command until its output ends.
+// The idle timeout is only a safety net: when no output arrives for that long, cancel is called and the command counts as stalled.
+func (r *Reactor) React(cancel func()) Outcome {
 	scanner := bufio.NewScanner(r.reader)
 	scanner.Split(SplitRedisCommand)
 
 	scanCh := make(chan string, 10)
-	ticker := time.NewTicker(1 * time.Second)
+	done := make(chan struct{})
+	defer close(done)
 
 	go func() {
+		defer close(scanCh)
 		for scanner.Scan() {
-			scanCh <- scanner.Text()
+			select {
+			case scanCh <- scanner.Text():
+			case <-done:
+				return
+			}
 		}
-		close(scanCh)
 	}()
 
-	count := atomic.Int64{}
+	idle := time.NewTimer(r.idleTimeout)
+	defer idle.Stop()
 
-loop:
 	for {
 		select {
-		case <-ticker.C:
-			if v := count.Swap(0); v == 0 {
-				log.Debug().Int64("value", v).Msg("no command")
-				cancel()
-				break loop
-			}
+		case <-idle.C:
+			log.Warn().Dur("idleTimeout", r.idleTimeout).Msg("no output from command")
+			cancel()
+			return OutcomeStalled
 		case command, ok := <-scanCh:
 			if !ok {
-				cancel()
-				break loop
+				r.handleOutput("\n")
+				if len(r.errorLines) > 0 {
+					return OutcomeErrored
+				}
+				return OutcomeFinished
 			}
-			count.Add(1)
+
+			idle.Reset(r.idleTimeout)
+			r.handleOutput(command)
+
 			command = strings.TrimSpace(command)
 			for k := range r.reaction {
 				if strings.Contains(command, k) {
 					log.Debug().Msgf("reactor: %s", r.reaction[k])
 					fmt.Fprintln(r.writer, r.reaction[k])
-					continue loop
+					break
 				}
 			}
 		}
 	}
 }
+
+// ErrorLines returns the error messages printed by the command.
+func (r *Reactor) ErrorLines() []string {
+	return r.errorLines
+}
+
+func (r *Reactor) handleOutput(token string) {
+	r.line = append(r.line, token...)
+	for {
+		idx := strings.IndexByte(string(r.line), '\n')
+		if idx < 0 {
+			return
+		}
+
+		line := strings.TrimSpace(string(r.line[:idx]))
+		r.line = r.line[idx+1:]
+
+		if isErrorLine(line) {
+			r.errorLines = append(r.errorLines, line)
+		}
+		if r.progress != nil {
+			r.progress.parseLine(line)
+		}
+	}
+}
+
+type Outcome string
+
+const (
+	OutcomeFinished Outcome = "finished"
+	OutcomeStalled  Outcome = "stalled"
+	OutcomeErrored  Outcome = "errored"
+)
+
+var (
+	ErrCommandStalled = errors.New("command stalled")
+	ErrCommandErrored = errors.New("command errored")
+)
+
+// OutcomeError describes an interactive command that did not finish.
+type OutcomeError struct {
+	Command  string
+	Outcome  Outcome
+	ExitCode int
+	Lines    []string
+	Err      error
+}
+
+func (e *OutcomeError) Error() string {
+	msg := fmt.Sprintf("%s %s with exit code %d", e.Command, e.Outcome, e.ExitCode)
+	if len(e.Lines) > 0 {
+		msg += ": " + strings.Join(e.Lines, "; ")
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *OutcomeError) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	for _, line := range e.Lines {
+		if kind := classifyMessage(line); kind != nil {
+			errs = append(errs, kind)
+			break
+		}
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+func (e *OutcomeError) Is(target error) bool {
+	switch target {
+	case ErrCommandStalled:
+		return e.Outcome == OutcomeStalled
+	case ErrCommandErrored:
+		return e.Outcome == OutcomeErrored
+	}
+	return false
+}
+
+var (
+	movingSlotPattern    = regexp.MustCompile(`^Moving slot (\d+) from (\S+) to (\S+):`)
+	plannedSlotPattern   = regexp.MustCompile(`^Moving slot (\d+) from (\S+)$`)
+	movingSlotsPattern   = regexp.MustCompile(`^Moving (\d+) slots from (\S+) to (\S+)$`)
+	addingReplicaPattern = regexp.MustCompile(`^Adding replica (\S+) to (\S+)$`)
+)
+
+// progressParser turns the output of the --cluster commands of valkey-cli and redis-cli into Progress events.
+// Events that are not about a single slot carry -1 as slot.
+type progressParser struct {
+	operation string
+	report    ProgressFunc
+	line      []byte
+	source    string
+	target    string
+	done      int
+	total     int
+}
+
+func newProgressParser(operation string, report ProgressFunc) *progressParser {
+	return &progressParser{
+		operation: operation,
+		report:    report,
+	}
+}
+
+func (p *progressParser) Write(data []byte) (int, error) {
+	for _, b := range data {
+		if b != '\n' {
+			p.line = append(p.line, b)
+			continue
+		}
+		p.parseLine(strings.TrimSpace(string(p.line)))
+		p.line = p.line[:0]
+	}
+	return len(data), nil
+}
+
+func (p *progressParser) parseLine(line string) {
+	if p.report == nil || line == "" {
+		return
+	}
+
+	if match := movingSlotPattern.FindStringSubmatch(line); match != nil {
+		slot, _ := strconv.Atoi(match[1])
+		p.done++
+		p.emit(slot, match[2], match[3], "")
+		return
+	}
+
+	if plannedSlotPattern.MatchString(line) {
+		p.total++
+		return
+	}
+
+	if match := movingSlotsPattern.FindStringSubmatch(line); match != nil {
+		count, _ := strconv.Atoi(match[1])
+		p.total += count
+		p.source, p.target = match[2], match[3]
+		p.emit(-1, p.source, p.target, line)
+		return
+	}
+
+	// rebalance prints a # per moved slot
+	if strings.Trim(line, "#") == "" {
+		p.done += len(line)
+		p.emit(-1, p.source, p.target, "")
+		return
+	}
+
+	if match := addingReplicaPattern.FindStringSubmatch(line); match != nil {
+		p.emit(-1, match[1], match[2], line)
+		return
+	}
+
+	if message, ok := strings.CutPrefix(line, ">>> "); ok {
+		p.emit(-1, "", "", message)
+	}
+}
+
+func (p *progressParser) emit(slot int, source string, target string, message string) {
+	p.report(Progress{
+		Operation: p.operation,
+		Slot:      slot,
+		Source:    source,
+		Target:    target,
+		Done:      p.done,
+		Total:     max(p.total, p.done),
+		Message:   message,
+	})
+}
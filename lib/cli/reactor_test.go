@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const reshardOutput = `>>> Performing Cluster Check (using node 127.0.0.1:7001)
+[OK] All 16384 slots covered.
+How many slots do you want to move (from 1 to 16384)? 2
+What is the receiving node ID? 6f3a9c0e4d1b2a7c8e9f0a1b2c3d4e5f6a7b8c9d
+Ready to move 2 slots.
+  Source nodes:
+    M: 1c2f1d5b4e6a7f8e9d0c1b2a3f4e5d6c7b8a9f0e 127.0.0.1:7001
+    Moving slot 0 from 1c2f1d5b4e6a7f8e9d0c1b2a3f4e5d6c7b8a9f0e
+    Moving slot 1 from 1c2f1d5b4e6a7f8e9d0c1b2a3f4e5d6c7b8a9f0e
+Do you want to proceed with the proposed reshard plan (yes/no)? yes
+Moving slot 0 from 127.0.0.1:7001 to 127.0.0.1:7005: ..
+Moving slot 1 from 127.0.0.1:7001 to 127.0.0.1:7005: 
+`
+
+const rebalanceOutput = `>>> Performing Cluster Check (using node 127.0.0.1:7001)
+>>> Rebalancing across 4 nodes. Total weight = 4.00
+Moving 3 slots from 127.0.0.1:7001 to 127.0.0.1:7005
+###
+`
+
+func TestProgressParser(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		operation string
+		events    int
+		last      Progress
+	}{
+		{
+			name:      "reshard through reactor tokens",
+			output:    reshardOutput,
+			operation: "reshard",
+			events:    3,
+			last:      Progress{Operation: "reshard", Slot: 1, Source: "127.0.0.1:7001", Target: "127.0.0.1:7005", Done: 2, Total: 2},
+		},
+		{
+			name:      "rebalance",
+			output:    rebalanceOutput,
+			operation: "rebalance",
+			events:    4,
+			last:      Progress{Operation: "rebalance", Slot: -1, Source: "127.0.0.1:7001", Target: "127.0.0.1:7005", Done: 3, Total: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make([]Progress, 0)
+			parser := newProgressParser(tt.operation, func(p Progress) {
+				events = append(events, p)
+			})
+
+			// feed the output the way React does, split at prompts
+			scanner := bufio.NewScanner(strings.NewReader(tt.output))
+			scanner.Split(SplitRedisCommand)
+			for scanner.Scan() {
+				parser.Write(scanner.Bytes())
+			}
+
+			if len(events) != tt.events {
+				t.Fatalf("got %d events %v, want %d", len(events), events, tt.events)
+			}
+			if got := events[len(events)-1]; got != tt.last {
+				t.Errorf("last event = %+v, want %+v", got, tt.last)
+			}
+		})
+	}
+}
+
+func TestReactorOutcome(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		outcome Outcome
+		lines   int
+	}{
+		{
+			name:    "finished",
+			output:  reshardOutput,
+			outcome: OutcomeFinished,
+		},
+		{
+			name:    "errored",
+			output:  ">>> Performing Cluster Check (using node 127.0.0.1:7001)\n[ERR] Nodes don't agree about configuration!\n*** Please fix your cluster problems before resharding\n",
+			outcome: OutcomeErrored,
+			lines:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reactor := NewReactor(strings.NewReader(tt.output), io.Discard)
+			reactor.AddReaction("How many slots do you want to move", "2")
+
+			if got := reactor.React(func() { t.Error("cancel called") }); got != tt.outcome {
+				t.Errorf("outcome = %s, want %s", got, tt.outcome)
+			}
+			if got := len(reactor.ErrorLines()); got != tt.lines {
+				t.Errorf("got %d error lines %v, want %d", got, reactor.ErrorLines(), tt.lines)
+			}
+		})
+	}
+
+	t.Run("stalled", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		defer pw.Close()
+
+		reactor := NewReactor(pr, io.Discard)
+		reactor.SetIdleTimeout(50 * time.Millisecond)
+
+		canceled := false
+		if got := reactor.React(func() { canceled = true }); got != OutcomeStalled {
+			t.Errorf("outcome = %s, want %s", got, OutcomeStalled)
+		}
+		if !canceled {
+			t.Error("cancel not called")
+		}
+	})
+}
+
+func TestOutcomeError(t *testing.T) {
+	err := error(&OutcomeError{Command: "valkey-cli", Outcome: OutcomeStalled, ExitCode: -1})
+	if !errors.Is(err, ErrCommandStalled) || errors.Is(err, ErrCommandErrored) {
+		t.Errorf("%v does not match its outcome", err)
+	}
+}
+
+const fakeReshard = `#!/bin/sh
+printf '>>> Performing Cluster Check (using node 127.0.0.1:7001)\n[OK] All 16384 slots covered.\n'
+printf 'How many slots do you want to move (from 1 to 16384)? '
+read slots
+printf 'What is the receiving node ID? '
+read target
+printf 'Please enter all the source node IDs.\nSource node #1: '
+read source
+printf 'Source node #2: '
+read done
+printf 'Do you want to proceed with the proposed reshard plan (yes/no)? '
+read answer
+[ "$slots $target $source $done $answer" = "2 target source done yes" ] || exit 1
+printf 'Moving slot 0 from 127.0.0.1:7001 to 127.0.0.1:7005: \n'
+printf 'Moving slot 1 from 127.0.0.1:7001 to 127.0.0.1:7005: \n'
+`
+
+const fakeStalledReshard = `#!/bin/sh
+printf '>>> Performing Cluster Check (using node 127.0.0.1:7001)\n'
+sleep 60
+`
+
+func TestReshard(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    error
+	}{
+		{
+			name:   "finished",
+			script: fakeReshard,
+		},
+		{
+			name:   "stalled",
+			script: fakeStalledReshard,
+			err:    ErrCommandStalled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, string(Valkey)), []byte(tt.script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			cli := New(Valkey, "", WithIdleTimeout(200*time.Millisecond))
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- cli.Reshard(context.Background(), "127.0.0.1", 7001, "target", 2, "source")
+			}()
+
+			select {
+			case err := <-errCh:
+				if tt.err == nil && err != nil {
+					t.Errorf("Reshard() error = %v", err)
+				}
+				if tt.err != nil && !errors.Is(err, tt.err) {
+					t.Errorf("Reshard() error = %v, want %v", err, tt.err)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("Reshard() did not return")
+			}
+		})
+	}
+}
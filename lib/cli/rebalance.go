@@ -0,0 +1,398 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultRebalanceThreshold is the deviation in percent from the expected slot count tolerated by `--cluster rebalance`.
+const DefaultRebalanceThreshold = 2.0
+
+type RebalanceOptions struct {
+	// Weights maps node IDs to their weight, masters not listed weigh 1 and a weight of 0 drains the master.
+	Weights map[string]float64
+	// Threshold is the deviation in percent from the expected slot count under which no slot is moved.
+	Threshold float64
+	// UseEmptyMasters lets masters without slots take part in the rebalance.
+	UseEmptyMasters bool
+	// SlotBytes balances the bytes of data held by the masters instead of their slot counts, see MeasureSlots.
+	// Threshold then applies to the bytes, and only slots holding data are moved unless a master is drained.
+	SlotBytes map[int]int64
+}
+
+type SlotMove struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Slots  SlotSet `json:"slots"`
+}
+
+type RebalancePlan struct {
+	Expected map[string]int `json:"expected"`
+	// ExpectedBytes is the share of data of every master when the plan balances SlotBytes.
+	ExpectedBytes map[string]int64 `json:"expected_bytes,omitempty"`
+	Moves         []SlotMove       `json:"moves"`
+}
+
+func (p *RebalancePlan) SlotCount() int {
+	count := 0
+	for _, move := range p.Moves {
+		count += move.Slots.Count()
+	}
+	return count
+}
+
+type rebalanceNode struct {
+	node     *ClusterNode
+	slots    SlotSet
+	expected int
+	balance  int
+}
+
+// PlanRebalance computes the slot moves bringing every master to its share of the assigned slots according to its weight.
+// Only the surplus of a master is moved, so the plan is the minimal set of moves for the target distribution.
+func PlanRebalance(nodes []*ClusterNode, opts RebalanceOptions) (*RebalancePlan, error) {
+	participants := make([]*rebalanceNode, 0)
+	totalWeight := 0.0
+	assigned := 0
+	for _, node := range nodes {
+		if !node.IsMaster() || node.IsFailing() {
+			continue
+		}
+		if node.Slots.IsEmpty() && !opts.UseEmptyMasters {
+			continue
+		}
+
+		weight, ok := opts.Weights[node.ID]
+		if !ok {
+			weight = 1
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("invalid weight %v of node %s", weight, node.ID)
+		}
+
+		participants = append(participants, &rebalanceNode{node: node, slots: node.Slots})
+		totalWeight += weight
+		assigned += node.Slots.Count()
+	}
+
+	for id := range opts.Weights {
+		if !slices.ContainsFunc(nodes, func(node *ClusterNode) bool { return node.ID == id && node.IsMaster() }) {
+			return nil, fmt.Errorf("weighted node %s: %w", id, ErrNodeNotFound)
+		}
+	}
+
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("total weight of the masters is zero")
+	}
+
+	slices.SortFunc(participants, func(a, b *rebalanceNode) int {
+		return strings.Compare(a.node.ID, b.node.ID)
+	})
+
+	// without any data measured there is nothing but the slot counts to balance
+	if total := totalBytes(participants, opts.SlotBytes); total > 0 {
+		return planRebalanceBytes(participants, opts, totalWeight, total), nil
+	}
+
+	// floor every share and hand the remainder to the largest fractions so the shares sum up to the assigned slots
+	fractions := make([]float64, len(participants))
+	distributed := 0
+	for i, p := range participants {
+		weight, ok := opts.Weights[p.node.ID]
+		if !ok {
+			weight = 1
+		}
+		share := float64(assigned) * weight / totalWeight
+		p.expected = int(math.Floor(share))
+		fractions[i] = share - float64(p.expected)
+		distributed += p.expected
+	}
+	order := make([]int, len(participants))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case fractions[a] > fractions[b]:
+			return -1
+		case fractions[a] < fractions[b]:
+			return 1
+		}
+		return 0
+	})
+	for i := 0; distributed < assigned; i++ {
+		participants[order[i%len(order)]].expected++
+		distributed++
+	}
+
+	plan := &RebalancePlan{
+		Expected: make(map[string]int, len(participants)),
+		Moves:    make([]SlotMove, 0),
+	}
+
+	exceeded := false
+	for _, p := range participants {
+		plan.Expected[p.node.ID] = p.expected
+		p.balance = p.slots.Count() - p.expected
+		if p.balance == 0 {
+			continue
+		}
+		if p.expected == 0 || math.Abs(float64(p.balance))*100/float64(p.expected) > opts.Threshold {
+			exceeded = true
+		}
+	}
+	if !exceeded {
+		return plan, nil
+	}
+
+	sources := make([]*rebalanceNode, 0)
+	targets := make([]*rebalanceNode, 0)
+	for _, p := range participants {
+		switch {
+		case p.balance > 0:
+			sources = append(sources, p)
+		case p.balance < 0:
+			targets = append(targets, p)
+		}
+	}
+	slices.SortStableFunc(sources, func(a, b *rebalanceNode) int {
+		return b.balance - a.balance
+	})
+	slices.SortStableFunc(targets, func(a, b *rebalanceNode) int {
+		return a.balance - b.balance
+	})
+
+	for s, t := 0, 0; s < len(sources) && t < len(targets); {
+		source, target := sources[s], targets[t]
+
+		count := min(source.balance, -target.balance)
+		slots := source.slots.Tail(count)
+		source.slots = source.slots.Subtract(slots)
+		source.balance -= count
+		target.balance += count
+
+		plan.Moves = append(plan.Moves, SlotMove{
+			Source: source.node.ID,
+			Target: target.node.ID,
+			Slots:  slots,
+		})
+
+		if source.balance == 0 {
+			s++
+		}
+		if target.balance == 0 {
+			t++
+		}
+	}
+
+	return plan, nil
+}
+
+func totalBytes(participants []*rebalanceNode, slotBytes map[int]int64) int64 {
+	total := int64(0)
+	for _, p := range participants {
+		for _, r := range p.slots {
+			for slot := r.Start; slot <= r.End; slot++ {
+				total += slotBytes[slot]
+			}
+		}
+	}
+	return total
+}
+
+type byteNode struct {
+	id       string
+	weight   float64
+	count    int
+	balance  float64
+	expected float64
+	// slots are the slots the master owned before the plan, sorted by their bytes
+	slots []int
+}
+
+// planRebalanceBytes moves single slots from the masters holding the most data above their share to the masters
+// holding the least. A slot is only moved when it narrows the gap between both, so a slot larger than the gap stays.
+func planRebalanceBytes(participants []*rebalanceNode, opts RebalanceOptions, totalWeight float64, total int64) *RebalancePlan {
+	plan := &RebalancePlan{
+		Expected:      make(map[string]int, len(participants)),
+		ExpectedBytes: make(map[string]int64, len(participants)),
+		Moves:         make([]SlotMove, 0),
+	}
+
+	nodes := make([]*byteNode, 0, len(participants))
+	exceeded := false
+	for _, p := range participants {
+		weight, ok := opts.Weights[p.node.ID]
+		if !ok {
+			weight = 1
+		}
+
+		n := &byteNode{
+			id:       p.node.ID,
+			weight:   weight,
+			count:    p.slots.Count(),
+			expected: float64(total) * weight / totalWeight,
+			slots:    p.slots.Slots(),
+		}
+		slices.SortFunc(n.slots, func(a, b int) int {
+			if opts.SlotBytes[a] != opts.SlotBytes[b] {
+				return cmp.Compare(opts.SlotBytes[a], opts.SlotBytes[b])
+			}
+			return a - b
+		})
+		for _, slot := range n.slots {
+			n.balance += float64(opts.SlotBytes[slot])
+		}
+		n.balance -= n.expected
+		nodes = append(nodes, n)
+
+		plan.ExpectedBytes[n.id] = int64(math.Round(n.expected))
+		if n.expected == 0 {
+			exceeded = exceeded || n.count > 0
+		} else if math.Abs(n.balance)*100/n.expected > opts.Threshold {
+			exceeded = true
+		}
+	}
+
+	moved := make(map[[2]string]SlotSet)
+	move := func(source, target *byteNode, i int) {
+		slot := source.slots[i]
+		source.slots = slices.Delete(source.slots, i, i+1)
+		source.balance -= float64(opts.SlotBytes[slot])
+		source.count--
+		target.balance += float64(opts.SlotBytes[slot])
+		target.count++
+
+		key := [2]string{source.id, target.id}
+		moved[key] = moved[key].Union(SlotSetOf(slot))
+	}
+
+	// receiver returns the master short of the most data, or of the most slots for a slot without data
+	receiver := func(bySlots bool) *byteNode {
+		var picked *byteNode
+		for _, n := range nodes {
+			if n.weight == 0 {
+				continue
+			}
+			if picked == nil || (bySlots && n.count < picked.count) || (!bySlots && n.balance < picked.balance) {
+				picked = n
+			}
+		}
+		return picked
+	}
+
+	if exceeded {
+		for _, source := range nodes {
+			if source.weight != 0 {
+				continue
+			}
+			for len(source.slots) > 0 {
+				last := len(source.slots) - 1
+				move(source, receiver(opts.SlotBytes[source.slots[last]] == 0), last)
+			}
+		}
+
+		exhausted := make(map[string]bool)
+		for {
+			var source *byteNode
+			for _, n := range nodes {
+				if n.weight == 0 || n.balance <= 0 || exhausted[n.id] {
+					continue
+				}
+				if source == nil || n.balance > source.balance {
+					source = n
+				}
+			}
+			target := receiver(false)
+			if source == nil || target == nil || target.balance >= 0 {
+				break
+			}
+
+			// prefer the largest slot filling the smaller of both gaps, otherwise the smallest slot still narrowing them
+			limit := min(source.balance, -target.balance)
+			i, _ := slices.BinarySearchFunc(source.slots, limit, func(slot int, limit float64) int {
+				if float64(opts.SlotBytes[slot]) <= limit {
+					return -1
+				}
+				return 1
+			})
+			pick := -1
+			switch {
+			case i > 0 && opts.SlotBytes[source.slots[i-1]] > 0:
+				pick = i - 1
+			case i < len(source.slots) && float64(opts.SlotBytes[source.slots[i]]) < source.balance-target.balance:
+				pick = i
+			}
+			if pick < 0 {
+				exhausted[source.id] = true
+				continue
+			}
+
+			move(source, target, pick)
+		}
+	}
+
+	for _, n := range nodes {
+		plan.Expected[n.id] = n.count
+	}
+
+	keys := slices.Collect(maps.Keys(moved))
+	slices.SortFunc(keys, func(a, b [2]string) int {
+		if c := strings.Compare(a[0], b[0]); c != 0 {
+			return c
+		}
+		return strings.Compare(a[1], b[1])
+	})
+	for _, key := range keys {
+		plan.Moves = append(plan.Moves, SlotMove{Source: key[0], Target: key[1], Slots: moved[key]})
+	}
+
+	return plan
+}
+
+// ExecuteRebalance runs the moves of the plan in order, reporting the progress of every slot through WithProgress.
+func (cli *CLI) ExecuteRebalance(ctx context.Context, host string, port int, plan *RebalancePlan) error {
+	log.Info().Int("moves", len(plan.Moves)).Int("slots", plan.SlotCount()).Msg("rebalance")
+
+	m, err := cli.newMigrator(ctx, host, port)
+	if err != nil {
+		return err
+	}
+	defer m.close()
+
+	for _, move := range plan.Moves {
+		if err := m.migrate(ctx, move.Source, move.Target, move.Slots); err != nil {
+			return fmt.Errorf("failed to move slots %s from %s to %s: %w", move.Slots, move.Source, move.Target, err)
+		}
+	}
+
+	log.Info().Msg("finish rebalance")
+
+	return nil
+}
+
+// RebalanceWith plans a rebalance of the cluster with the given options and executes it.
+func (cli *CLI) RebalanceWith(ctx context.Context, host string, port int, opts RebalanceOptions) (*RebalancePlan, error) {
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	plan, err := PlanRebalance(nodes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cli.ExecuteRebalance(ctx, host, port, plan); err != nil {
+		return plan, err
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestPlanRebalance(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 8191}}},
+		{ID: "b", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 8192, End: 16383}}},
+		{ID: "c", Flags: NewNodeFlags(FlagMaster)},
+		{ID: "d", Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     RebalanceOptions
+		expected map[string]int
+		moved    int
+	}{
+		{
+			name:     "balanced without empty masters",
+			opts:     RebalanceOptions{Threshold: DefaultRebalanceThreshold},
+			expected: map[string]int{"a": 8192, "b": 8192},
+			moved:    0,
+		},
+		{
+			name:     "empty master takes a third",
+			opts:     RebalanceOptions{Threshold: DefaultRebalanceThreshold, UseEmptyMasters: true},
+			expected: map[string]int{"a": 5462, "b": 5461, "c": 5461},
+			moved:    5461,
+		},
+		{
+			name:     "weights",
+			opts:     RebalanceOptions{Weights: map[string]float64{"a": 3, "b": 1}},
+			expected: map[string]int{"a": 12288, "b": 4096},
+			moved:    4096,
+		},
+		{
+			name:     "zero weight drains",
+			opts:     RebalanceOptions{Weights: map[string]float64{"b": 0}, UseEmptyMasters: true},
+			expected: map[string]int{"a": 8192, "b": 0, "c": 8192},
+			moved:    8192,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanRebalance(nodes, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for id, want := range tt.expected {
+				if got := plan.Expected[id]; got != want {
+					t.Errorf("Expected[%s] = %d, want %d", id, got, want)
+				}
+			}
+			if got := plan.SlotCount(); got != tt.moved {
+				t.Errorf("SlotCount() = %d, want %d", got, tt.moved)
+			}
+			for _, move := range plan.Moves {
+				if move.Source == "d" || move.Target == "d" {
+					t.Errorf("replica d takes part in move %v", move)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanRebalanceUnknownWeight(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 16383}}},
+	}
+
+	if _, err := PlanRebalance(nodes, RebalanceOptions{Weights: map[string]float64{"x": 1}}); err == nil {
+		t.Errorf("PlanRebalance() with unknown weighted node succeeded")
+	}
+}
+
+func TestPlanDrain(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 99}, {Start: 200, End: 299}}},
+		{ID: "b", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 100, End: 149}}},
+		{ID: "c", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 150, End: 199}, {Start: 300, End: 399}}},
+		{ID: "d", Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+	}
+
+	moves, err := PlanDrain(nodes, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int{}
+	moved := SlotSet{}
+	for _, move := range moves {
+		if move.Source != "a" {
+			t.Errorf("move from %s, want a", move.Source)
+		}
+		got[move.Target] += move.Slots.Count()
+		moved = moved.Union(move.Slots)
+	}
+
+	// b catches up with c first, the rest is split evenly
+	if got["b"] != 150 || got["c"] != 50 {
+		t.Errorf("moved %v, want b: 150, c: 50", got)
+	}
+	if moved.String() != nodes[0].Slots.String() {
+		t.Errorf("moved %s, want %s", moved, nodes[0].Slots)
+	}
+
+	if _, err := PlanDrain(nodes[:1], "a"); err == nil {
+		t.Error("PlanDrain without other masters succeeded")
+	}
+}
+
+func TestPlanRebalanceBytes(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 8191}}},
+		{ID: "b", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 8192, End: 16383}}},
+		{ID: "c", Flags: NewNodeFlags(FlagMaster)},
+	}
+
+	tests := []struct {
+		name  string
+		opts  RebalanceOptions
+		moved map[string]string
+		bytes map[string]int64
+	}{
+		{
+			name: "hot slots are split",
+			opts: RebalanceOptions{
+				Threshold: DefaultRebalanceThreshold,
+				SlotBytes: map[int]int64{0: 1000, 1: 1000, 8192: 100},
+			},
+			moved: map[string]string{"a>b": "0"},
+			bytes: map[string]int64{"a": 1000, "b": 1100},
+		},
+		{
+			name: "within threshold",
+			opts: RebalanceOptions{
+				Threshold: 10,
+				SlotBytes: map[int]int64{0: 1000, 8192: 950},
+			},
+			moved: map[string]string{},
+			bytes: map[string]int64{"a": 1000, "b": 950},
+		},
+		{
+			name: "slot larger than the gap stays",
+			opts: RebalanceOptions{
+				SlotBytes: map[int]int64{0: 5000, 8192: 100, 8193: 100},
+			},
+			moved: map[string]string{},
+			bytes: map[string]int64{"a": 5000, "b": 200},
+		},
+		{
+			name: "empty master takes data",
+			opts: RebalanceOptions{
+				UseEmptyMasters: true,
+				SlotBytes:       map[int]int64{0: 300, 1: 300, 2: 300, 8192: 300, 8193: 300, 8194: 300},
+			},
+			moved: map[string]string{"a>c": "2", "b>c": "8194"},
+			bytes: map[string]int64{"a": 600, "b": 600, "c": 600},
+		},
+		{
+			name: "zero weight drains every slot",
+			opts: RebalanceOptions{
+				Weights:         map[string]float64{"b": 0},
+				UseEmptyMasters: true,
+				SlotBytes:       map[int]int64{0: 100, 8192: 400, 8193: 200},
+			},
+			moved: map[string]string{"b>a": "8193", "b>c": "8192,8194-16383"},
+			bytes: map[string]int64{"a": 300, "b": 0, "c": 400},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanRebalance(nodes, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			moved := map[string]string{}
+			for _, move := range plan.Moves {
+				moved[move.Source+">"+move.Target] = move.Slots.String()
+			}
+			if len(moved) != len(tt.moved) {
+				t.Errorf("moves = %v, want %v", moved, tt.moved)
+			}
+			for key, want := range tt.moved {
+				if moved[key] != want {
+					t.Errorf("moves[%s] = %s, want %s", key, moved[key], want)
+				}
+			}
+
+			// the bytes every master holds after the plan
+			owners := NewSlotMap(nodes)
+			for _, move := range plan.Moves {
+				for _, slot := range move.Slots.Slots() {
+					owners.owners[slot] = []string{move.Target}
+				}
+			}
+			bytes := map[string]int64{}
+			for slot, b := range tt.opts.SlotBytes {
+				bytes[owners.Owner(slot)] += b
+			}
+			for id, want := range tt.bytes {
+				if bytes[id] != want {
+					t.Errorf("bytes[%s] = %d, want %d", id, bytes[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanRebalanceBytesWithoutData(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 16383}}},
+		{ID: "b", Flags: NewNodeFlags(FlagMaster)},
+	}
+
+	plan, err := PlanRebalance(nodes, RebalanceOptions{UseEmptyMasters: true, SlotBytes: map[int]int64{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.SlotCount() != 8192 || plan.ExpectedBytes != nil {
+		t.Errorf("plan = %+v, want slot count rebalance moving 8192 slots", plan)
+	}
+}
@@ -0,0 +1,374 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+type ReconcileAction string
+
+const (
+	ReconcileCreate     ReconcileAction = "create"
+	ReconcileAddNode    ReconcileAction = "add_node"
+	ReconcileFailover   ReconcileAction = "failover"
+	ReconcileRebalance  ReconcileAction = "rebalance"
+	ReconcileReplicate  ReconcileAction = "replicate"
+	ReconcileDeleteNode ReconcileAction = "delete_node"
+	ReconcileForgetNode ReconcileAction = "forget_node"
+)
+
+type ReconcileStep struct {
+	Action ReconcileAction `json:"action"`
+	// Address is the node the step is about, Addresses the masters of a create.
+	Address   string   `json:"address,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+	NodeID    string   `json:"node_id,omitempty"`
+	// Master is the address of the master a replica should follow.
+	Master    string         `json:"master,omitempty"`
+	Mode      FailoverMode   `json:"mode,omitempty"`
+	Rebalance *RebalancePlan `json:"rebalance,omitempty"`
+}
+
+type ReconcilePlan struct {
+	// Seed is the node the steps are run through.
+	Seed  string          `json:"seed"`
+	Steps []ReconcileStep `json:"steps"`
+}
+
+type ReconcileOptions struct {
+	// DryRun only returns the plan.
+	DryRun bool
+}
+
+// PlanReconcile computes the steps converging the cluster seen in nodes to the spec.
+// The steps run in order: create or add the missing nodes, promote replicas declared as masters, move the slots
+// according to the weights, attach the replicas and finally delete the nodes the spec does not list.
+// Nodes that are not in the cluster yet are identified by their address in the rebalance plan.
+func PlanReconcile(spec *ClusterSpec, nodes []*ClusterNode, seedHost string) (*ReconcilePlan, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	plan := &ReconcilePlan{
+		Steps: make([]ReconcileStep, 0),
+	}
+
+	// sim is the cluster as it will look after the steps planned so far, keyed by address
+	sim := make(map[string]*ClusterNode)
+	removed := make([]*ClusterNode, 0)
+
+	create := len(nodes) <= 1 && NewSlotMap(nodes).Assigned().IsEmpty()
+	if !create {
+		for _, node := range nodes {
+			if node.Flags.Has(FlagNoAddr) {
+				copied := *node
+				removed = append(removed, &copied)
+				continue
+			}
+
+			host, port, err := nodeAddress(node, seedHost)
+			if err != nil {
+				return nil, err
+			}
+			address := joinAddress(host, port)
+			if _, ok := spec.node(address); !ok {
+				copied := *node
+				copied.Host = host
+				removed = append(removed, &copied)
+				continue
+			}
+
+			// the planner changes roles and slots of its copies only
+			copied := *node
+			copied.Host = host
+			sim[address] = &copied
+			if plan.Seed == "" || node.IsMyself() {
+				plan.Seed = address
+			}
+		}
+	}
+
+	if create {
+		addresses := make([]string, 0)
+		for _, node := range spec.Nodes {
+			if node.Role == RoleMaster {
+				addresses = append(addresses, node.Address)
+			}
+		}
+		plan.Seed = addresses[0]
+		plan.Steps = append(plan.Steps, ReconcileStep{Action: ReconcileCreate, Addresses: addresses})
+
+		for i, address := range addresses {
+			sim[address] = newSimNode(address, evenSlots(i, len(addresses)))
+		}
+	}
+	if plan.Seed == "" {
+		return nil, fmt.Errorf("no node of the spec is part of the cluster")
+	}
+
+	for _, node := range spec.Nodes {
+		if _, ok := sim[node.Address]; ok {
+			continue
+		}
+		plan.Steps = append(plan.Steps, ReconcileStep{Action: ReconcileAddNode, Address: node.Address})
+		sim[node.Address] = newSimNode(node.Address, nil)
+	}
+
+	byID := make(map[string]*ClusterNode, len(sim)+len(removed))
+	for _, node := range sim {
+		byID[node.ID] = node
+	}
+	for _, node := range removed {
+		byID[node.ID] = node
+	}
+
+	for _, node := range spec.Nodes {
+		self := sim[node.Address]
+		if node.Role != RoleMaster || !self.IsReplica() {
+			continue
+		}
+
+		master, ok := byID[self.MasterID]
+		if ok && master.IsMaster() && spec.isMaster(master.Address()) {
+			return nil, fmt.Errorf("replica %s and its master %s are both masters in the spec", node.Address, master.Address())
+		}
+
+		mode := FailoverDefault
+		if !ok || master.IsFailing() {
+			mode = FailoverForce
+		}
+		plan.Steps = append(plan.Steps, ReconcileStep{Action: ReconcileFailover, Address: node.Address, NodeID: self.ID, Mode: mode})
+
+		self.Flags = NewNodeFlags(FlagMaster)
+		self.MasterID = ""
+		if ok {
+			self.Slots = master.Slots
+			master.Slots = nil
+			master.Flags = NewNodeFlags(FlagSlave)
+			master.MasterID = self.ID
+		}
+	}
+
+	participants := make([]*ClusterNode, 0, len(sim)+len(removed))
+	for _, node := range sim {
+		participants = append(participants, node)
+	}
+	for _, node := range removed {
+		if node.IsMaster() && !node.Slots.IsEmpty() {
+			if node.IsFailing() || node.Flags.Has(FlagNoAddr) {
+				return nil, fmt.Errorf("failing master %s still owns slots %s", node.ID, node.Slots)
+			}
+			participants = append(participants, node)
+		}
+	}
+	slices.SortFunc(participants, compareNodeID)
+
+	rebalance, err := PlanRebalance(participants, RebalanceOptions{
+		Weights:         reconcileWeights(spec, participants, seedHost),
+		Threshold:       spec.threshold(),
+		UseEmptyMasters: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rebalance.Moves) > 0 {
+		plan.Steps = append(plan.Steps, ReconcileStep{Action: ReconcileRebalance, Rebalance: rebalance})
+		for _, move := range rebalance.Moves {
+			byID[move.Source].Slots = byID[move.Source].Slots.Subtract(move.Slots)
+			byID[move.Target].Slots = byID[move.Target].Slots.Union(move.Slots)
+		}
+	}
+
+	members := make([]*ClusterNode, 0, len(sim))
+	for _, node := range sim {
+		members = append(members, node)
+	}
+	replicas, err := PlanReplicas(members, spec.replicas())
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range replicas.Assignments {
+		replica := byID[assignment.ReplicaID]
+		if spec.isMaster(replica.Address()) {
+			continue
+		}
+		plan.Steps = append(plan.Steps, ReconcileStep{
+			Action:  ReconcileReplicate,
+			Address: replica.Address(),
+			Master:  byID[assignment.MasterID].Address(),
+		})
+	}
+	for id, count := range replicas.Missing {
+		log.Warn().Str("master", byID[id].Address()).Int("missing", count).Msg("spec lacks replicas on other hosts")
+	}
+
+	slices.SortFunc(removed, compareNodeID)
+	for _, node := range removed {
+		step := ReconcileStep{Action: ReconcileDeleteNode, NodeID: node.ID}
+		if node.IsFailing() || node.Flags.Has(FlagNoAddr) {
+			step.Action = ReconcileForgetNode
+		} else {
+			step.Address = node.Address()
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	return plan, nil
+}
+
+// reconcileWeights gives every master its weight from the spec, masters the spec does not declare as masters weigh 0.
+func reconcileWeights(spec *ClusterSpec, nodes []*ClusterNode, seedHost string) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, node := range nodes {
+		if !node.IsMaster() || node.IsFailing() {
+			continue
+		}
+		host, port, err := nodeAddress(node, seedHost)
+		if err != nil {
+			weights[node.ID] = 0
+			continue
+		}
+		weights[node.ID] = spec.weight(joinAddress(host, port))
+	}
+	return weights
+}
+
+func newSimNode(address string, slots SlotSet) *ClusterNode {
+	host, port, _ := splitAddress(address)
+	return &ClusterNode{
+		ID:    address,
+		Host:  host,
+		Port:  port,
+		Flags: NewNodeFlags(FlagMaster),
+		Slots: slots,
+	}
+}
+
+// evenSlots returns the slots `--cluster create` assigns to the i-th of n masters.
+func evenSlots(i, n int) SlotSet {
+	start := i * MaxSlotCount / n
+	end := (i+1)*MaxSlotCount/n - 1
+	return SlotSet{{Start: start, End: end}}
+}
+
+func joinAddress(host string, port int) string {
+	return host + ":" + strconv.Itoa(port)
+}
+
+// Reconcile compares the cluster reachable through host:port with the spec and runs the steps converging it.
+// The returned plan lists the steps, with DryRun nothing is changed.
+func (cli *CLI) Reconcile(ctx context.Context, host string, port int, spec *ClusterSpec, opts ReconcileOptions) (*ReconcilePlan, error) {
+	log.Info().Str("host", host).Int("port", port).Bool("dryRun", opts.DryRun).Msg("reconcile cluster")
+
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	plan, err := PlanReconcile(spec, nodes, host)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	seedHost, seedPort, err := splitAddress(plan.Seed)
+	if err != nil {
+		return plan, err
+	}
+
+	for _, step := range plan.Steps {
+		if err := ctx.Err(); err != nil {
+			return plan, err
+		}
+
+		log.Info().Str("action", string(step.Action)).Str("address", step.Address).Str("nodeID", step.NodeID).Msg("reconcile step")
+
+		if err := cli.applyReconcileStep(ctx, seedHost, seedPort, spec, step); err != nil {
+			return plan, fmt.Errorf("failed to reconcile step %s %s: %w", step.Action, step.Address+step.NodeID, err)
+		}
+	}
+
+	log.Info().Int("steps", len(plan.Steps)).Msg("finish reconcile cluster")
+
+	return plan, nil
+}
+
+func (cli *CLI) applyReconcileStep(ctx context.Context, seedHost string, seedPort int, spec *ClusterSpec, step ReconcileStep) error {
+	switch step.Action {
+	case ReconcileCreate:
+		return cli.CreateCluster(ctx, 0, step.Addresses...)
+	case ReconcileAddNode:
+		host, port, err := splitAddress(step.Address)
+		if err != nil {
+			return err
+		}
+		return cli.AddNode(ctx, host, port, seedHost, seedPort)
+	case ReconcileFailover:
+		host, port, err := splitAddress(step.Address)
+		if err != nil {
+			return err
+		}
+		return cli.Failover(ctx, host, port, FailoverOptions{Mode: step.Mode})
+	case ReconcileRebalance:
+		// the node IDs of added nodes are only known now, so the moves are planned again
+		nodes, err := cli.GetClusterNodes(ctx, seedHost, seedPort)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster nodes: %w", err)
+		}
+		plan, err := PlanRebalance(nodes, RebalanceOptions{
+			Weights:         reconcileWeights(spec, nodes, seedHost),
+			Threshold:       spec.threshold(),
+			UseEmptyMasters: true,
+		})
+		if err != nil {
+			return err
+		}
+		return cli.ExecuteRebalance(ctx, seedHost, seedPort, plan)
+	case ReconcileReplicate:
+		nodes, err := cli.GetClusterNodes(ctx, seedHost, seedPort)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster nodes: %w", err)
+		}
+		idx := slices.IndexFunc(nodes, func(node *ClusterNode) bool {
+			host, port, err := nodeAddress(node, seedHost)
+			return err == nil && joinAddress(host, port) == step.Master
+		})
+		if idx < 0 {
+			return fmt.Errorf("master %s: %w", step.Master, ErrNodeNotFound)
+		}
+		host, port, err := splitAddress(step.Address)
+		if err != nil {
+			return err
+		}
+		return cli.ReplicateNode(ctx, host, port, nodes[idx].ID)
+	case ReconcileDeleteNode:
+		return cli.DeleteNode(ctx, seedHost, seedPort, step.NodeID)
+	case ReconcileForgetNode:
+		// a node that cannot be reached has to be forgotten by every other node
+		nodes, err := cli.GetClusterNodes(ctx, seedHost, seedPort)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster nodes: %w", err)
+		}
+		for _, node := range nodes {
+			if node.ID == step.NodeID || node.IsFailing() || node.Flags.Has(FlagNoAddr) {
+				continue
+			}
+			host, port, err := nodeAddress(node, seedHost)
+			if err != nil {
+				return err
+			}
+			if err := cli.ForgetNode(ctx, host, port, step.NodeID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown reconcile action %q", step.Action)
+}
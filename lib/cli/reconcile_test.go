@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+)
+
+func reconcileActions(plan *ReconcilePlan) []ReconcileAction {
+	actions := make([]ReconcileAction, 0, len(plan.Steps))
+	for _, step := range plan.Steps {
+		actions = append(actions, step.Action)
+	}
+	return actions
+}
+
+func TestParseClusterSpec(t *testing.T) {
+	yamlSpec := `
+replicas: 1
+nodes:
+  - address: 10.0.0.1:7001
+    role: master
+    weight: 2
+  - address: 10.0.0.2:7001
+    role: replica
+`
+	jsonSpec := `{"replicas": 1, "nodes": [{"address": "10.0.0.1:7001", "role": "master", "weight": 2}, {"address": "10.0.0.2:7001", "role": "replica"}]}`
+
+	for _, data := range []string{yamlSpec, jsonSpec} {
+		spec, err := ParseClusterSpec([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(spec.Nodes) != 2 || spec.weight("10.0.0.1:7001") != 2 || spec.weight("10.0.0.2:7001") != 0 {
+			t.Errorf("unexpected spec %+v", spec)
+		}
+	}
+
+	for _, data := range []string{
+		`nodes: [{address: "10.0.0.1:7001", role: replica}]`,
+		`nodes: [{address: "10.0.0.1", role: master}]`,
+		`nodes: [{address: "10.0.0.1:7001", role: leader}]`,
+		`nodes: [{address: "10.0.0.1:7001", role: master}, {address: "10.0.0.1:7001", role: master}]`,
+	} {
+		if _, err := ParseClusterSpec([]byte(data)); err == nil {
+			t.Errorf("ParseClusterSpec(%s) succeeded", data)
+		}
+	}
+}
+
+func TestPlanReconcile(t *testing.T) {
+	cluster := []*ClusterNode{
+		{ID: "a", Host: "10.0.0.1", Port: 7001, Flags: NewNodeFlags(FlagMyself, FlagMaster), Slots: SlotSet{{Start: 0, End: 8191}}},
+		{ID: "b", Host: "10.0.0.2", Port: 7001, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 8192, End: 16383}}},
+		{ID: "c", Host: "10.0.0.2", Port: 7002, Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+	}
+
+	tests := []struct {
+		name    string
+		nodes   []*ClusterNode
+		spec    ClusterSpec
+		actions []ReconcileAction
+		moved   int
+	}{
+		{
+			name:  "converged",
+			nodes: cluster,
+			spec: ClusterSpec{Nodes: []NodeSpec{
+				{Address: "10.0.0.1:7001", Role: RoleMaster},
+				{Address: "10.0.0.2:7001", Role: RoleMaster},
+				{Address: "10.0.0.2:7002", Role: RoleReplica},
+			}},
+			actions: []ReconcileAction{},
+		},
+		{
+			name:  "create",
+			nodes: []*ClusterNode{{ID: "a", Port: 7001, Flags: NewNodeFlags(FlagMyself, FlagMaster)}},
+			spec: ClusterSpec{Nodes: []NodeSpec{
+				{Address: "10.0.0.1:7001", Role: RoleMaster},
+				{Address: "10.0.0.2:7001", Role: RoleMaster},
+				{Address: "10.0.0.3:7001", Role: RoleMaster},
+				{Address: "10.0.0.1:7002", Role: RoleReplica},
+			}, Replicas: 1},
+			actions: []ReconcileAction{ReconcileCreate, ReconcileAddNode, ReconcileReplicate},
+		},
+		{
+			name:  "add weighted master",
+			nodes: cluster,
+			spec: ClusterSpec{Nodes: []NodeSpec{
+				{Address: "10.0.0.1:7001", Role: RoleMaster},
+				{Address: "10.0.0.2:7001", Role: RoleMaster},
+				{Address: "10.0.0.3:7001", Role: RoleMaster, Weight: 2},
+				{Address: "10.0.0.2:7002", Role: RoleReplica},
+			}},
+			actions: []ReconcileAction{ReconcileAddNode, ReconcileRebalance},
+			moved:   8192,
+		},
+		{
+			name:  "remove master",
+			nodes: cluster,
+			spec: ClusterSpec{Nodes: []NodeSpec{
+				{Address: "10.0.0.1:7001", Role: RoleMaster},
+				{Address: "10.0.0.2:7002", Role: RoleReplica},
+			}},
+			actions: []ReconcileAction{ReconcileRebalance, ReconcileDeleteNode},
+			moved:   8192,
+		},
+		{
+			name:  "promote replica",
+			nodes: cluster,
+			spec: ClusterSpec{Nodes: []NodeSpec{
+				{Address: "10.0.0.2:7001", Role: RoleMaster},
+				{Address: "10.0.0.2:7002", Role: RoleMaster},
+				{Address: "10.0.0.1:7001", Role: RoleReplica},
+			}, Replicas: 1},
+			actions: []ReconcileAction{ReconcileFailover},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanReconcile(&tt.spec, tt.nodes, "10.0.0.1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := reconcileActions(plan); !slices.Equal(got, tt.actions) {
+				t.Fatalf("actions = %v, want %v", got, tt.actions)
+			}
+			if plan.Seed != "10.0.0.1:7001" {
+				t.Errorf("Seed = %s, want 10.0.0.1:7001", plan.Seed)
+			}
+
+			for _, step := range plan.Steps {
+				if step.Action == ReconcileRebalance && step.Rebalance.SlotCount() != tt.moved {
+					t.Errorf("moved %d slots, want %d", step.Rebalance.SlotCount(), tt.moved)
+				}
+				if step.Action == ReconcileReplicate && step.Address == "10.0.0.1:7002" && step.Master == "10.0.0.1:7001" {
+					t.Errorf("replica placed on the host of its master")
+				}
+			}
+		})
+	}
+
+	// the input is left untouched
+	if cluster[0].Slots.Count() != 8192 || !cluster[2].IsReplica() {
+		t.Errorf("PlanReconcile modified its input")
+	}
+}
+
+func TestPlanReconcileConflictingMasters(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "a", Host: "10.0.0.1", Port: 7001, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 16383}}},
+		{ID: "b", Host: "10.0.0.2", Port: 7001, Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+	}
+	spec := &ClusterSpec{Nodes: []NodeSpec{
+		{Address: "10.0.0.1:7001", Role: RoleMaster},
+		{Address: "10.0.0.2:7001", Role: RoleMaster},
+	}}
+
+	if _, err := PlanReconcile(spec, nodes, "10.0.0.1"); err == nil {
+		t.Error("PlanReconcile succeeded")
+	}
+}
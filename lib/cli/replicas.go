@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+type ReplicaAssignment struct {
+	ReplicaID   string `json:"replica_id"`
+	ReplicaHost string `json:"replica_host"`
+	ReplicaPort int    `json:"replica_port"`
+	MasterID    string `json:"master_id"`
+	MasterHost  string `json:"master_host"`
+}
+
+type ReplicaPlan struct {
+	Assignments []ReplicaAssignment `json:"assignments"`
+	// Missing counts the replicas a master still lacks because no node on another host was available.
+	Missing map[string]int `json:"missing,omitempty"`
+}
+
+// PlanReplicas decides which node should follow which master so every master serving slots gets
+// replicas replicas, none of them on the host of its master and preferably each on a different host.
+// Replicas already placed well are kept, empty masters, orphaned, misplaced and surplus replicas are reassigned.
+func PlanReplicas(nodes []*ClusterNode, replicas int) (*ReplicaPlan, error) {
+	if replicas < 0 {
+		return nil, fmt.Errorf("invalid replica count %d", replicas)
+	}
+
+	masters := make(map[string]*ClusterNode)
+	masterIDs := make([]string, 0)
+	for _, node := range nodes {
+		if node.IsMaster() && !node.IsFailing() && !node.Slots.IsEmpty() {
+			masters[node.ID] = node
+			masterIDs = append(masterIDs, node.ID)
+		}
+	}
+	slices.Sort(masterIDs)
+
+	// hosts used by the kept replicas of each master
+	hosts := make(map[string]map[string]int, len(masters))
+	for id := range masters {
+		hosts[id] = make(map[string]int)
+	}
+
+	replicasOf := make(map[string][]*ClusterNode)
+	candidates := make([]*ClusterNode, 0)
+	for _, node := range nodes {
+		switch {
+		case node.IsFailing() || node.Flags.Has(FlagNoAddr) || node.Flags.Has(FlagHandshake):
+		case node.IsMaster() && node.Slots.IsEmpty():
+			candidates = append(candidates, node)
+		case node.IsReplica():
+			master, ok := masters[node.MasterID]
+			if !ok || master.Host == node.Host {
+				candidates = append(candidates, node)
+				continue
+			}
+			replicasOf[node.MasterID] = append(replicasOf[node.MasterID], node)
+		}
+	}
+
+	for _, id := range masterIDs {
+		kept := replicasOf[id]
+		slices.SortFunc(kept, compareNodeID)
+		for i, replica := range kept {
+			// a second replica on the same host does not add availability, so it is surplus as well
+			if i >= replicas || hosts[id][replica.Host] > 0 {
+				candidates = append(candidates, replica)
+				continue
+			}
+			hosts[id][replica.Host]++
+		}
+	}
+	slices.SortFunc(candidates, compareNodeID)
+
+	missing := make(map[string]int)
+	for _, id := range masterIDs {
+		if need := replicas - len(hosts[id]); need > 0 {
+			missing[id] = need
+		}
+	}
+
+	plan := &ReplicaPlan{
+		Assignments: make([]ReplicaAssignment, 0),
+	}
+
+	used := make(map[string]bool)
+	// hand out one replica per master per round so a shortage is spread over all masters
+	for progress := true; progress; {
+		progress = false
+		for _, id := range masterIDs {
+			if missing[id] == 0 {
+				continue
+			}
+
+			master := masters[id]
+			picked := pickReplica(candidates, used, master, hosts[id])
+			if picked == nil {
+				continue
+			}
+
+			used[picked.ID] = true
+			hosts[id][picked.Host]++
+			missing[id]--
+			progress = true
+
+			plan.Assignments = append(plan.Assignments, ReplicaAssignment{
+				ReplicaID:   picked.ID,
+				ReplicaHost: picked.Host,
+				ReplicaPort: picked.Port,
+				MasterID:    id,
+				MasterHost:  master.Host,
+			})
+		}
+	}
+
+	for id, count := range missing {
+		if count == 0 {
+			delete(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		plan.Missing = missing
+	}
+
+	return plan, nil
+}
+
+// pickReplica prefers a candidate on a host the master has no replica on yet, taken from the host with the most spare candidates.
+func pickReplica(candidates []*ClusterNode, used map[string]bool, master *ClusterNode, hosts map[string]int) *ClusterNode {
+	spare := make(map[string]int)
+	for _, candidate := range candidates {
+		if !used[candidate.ID] {
+			spare[candidate.Host]++
+		}
+	}
+
+	var picked *ClusterNode
+	for _, candidate := range candidates {
+		if used[candidate.ID] || candidate.Host == master.Host || hosts[candidate.Host] > 0 {
+			continue
+		}
+		if picked == nil || spare[candidate.Host] > spare[picked.Host] {
+			picked = candidate
+		}
+	}
+
+	return picked
+}
+
+func compareNodeID(a, b *ClusterNode) int {
+	return strings.Compare(a.ID, b.ID)
+}
+
+// ApplyReplicaPlan runs CLUSTER REPLICATE on every replica of the plan.
+func (cli *CLI) ApplyReplicaPlan(ctx context.Context, plan *ReplicaPlan) error {
+	for _, assignment := range plan.Assignments {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		log.Info().Str("replicaID", assignment.ReplicaID).Str("masterID", assignment.MasterID).Msg("assign replica")
+
+		if _, err := cli.do(ctx, assignment.ReplicaHost, assignment.ReplicaPort, "CLUSTER", "REPLICATE", assignment.MasterID); err != nil {
+			return fmt.Errorf("failed to replicate %s from %s: %w", assignment.MasterID, assignment.ReplicaID, err)
+		}
+	}
+
+	for id, count := range plan.Missing {
+		log.Warn().Str("masterID", id).Int("missing", count).Msg("master lacks replicas on other hosts")
+	}
+
+	return nil
+}
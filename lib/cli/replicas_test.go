@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestPlanReplicas(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "m1", Host: "10.0.0.1", Port: 7001, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 5460}}},
+		{ID: "m2", Host: "10.0.0.2", Port: 7002, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 5461, End: 10922}}},
+		{ID: "m3", Host: "10.0.0.3", Port: 7003, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 10923, End: 16383}}},
+		// well placed, kept
+		{ID: "r1", Host: "10.0.0.2", Port: 7004, Flags: NewNodeFlags(FlagSlave), MasterID: "m1"},
+		// same host as its master, moved
+		{ID: "r2", Host: "10.0.0.2", Port: 7005, Flags: NewNodeFlags(FlagSlave), MasterID: "m2"},
+		// empty master
+		{ID: "r3", Host: "10.0.0.1", Port: 7006, Flags: NewNodeFlags(FlagMaster)},
+	}
+
+	plan, err := PlanReplicas(nodes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	masterOf := make(map[string]string)
+	for _, node := range nodes {
+		if node.IsReplica() {
+			masterOf[node.ID] = node.MasterID
+		}
+	}
+	for _, assignment := range plan.Assignments {
+		if assignment.ReplicaHost == assignment.MasterHost {
+			t.Errorf("replica %s shares host %s with master %s", assignment.ReplicaID, assignment.MasterHost, assignment.MasterID)
+		}
+		masterOf[assignment.ReplicaID] = assignment.MasterID
+	}
+
+	if masterOf["r1"] != "m1" {
+		t.Errorf("r1 follows %q, want m1", masterOf["r1"])
+	}
+	followed := make(map[string]int)
+	for _, master := range masterOf {
+		followed[master]++
+	}
+	for _, id := range []string{"m1", "m2", "m3"} {
+		if followed[id] != 1 {
+			t.Errorf("%s has %d replicas, want 1", id, followed[id])
+		}
+	}
+	if len(plan.Missing) != 0 {
+		t.Errorf("Missing = %v, want none", plan.Missing)
+	}
+}
+
+func TestPlanReplicasMissing(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "m1", Host: "10.0.0.1", Port: 7001, Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 16383}}},
+		{ID: "e1", Host: "10.0.0.1", Port: 7002, Flags: NewNodeFlags(FlagMaster)},
+	}
+
+	plan, err := PlanReplicas(nodes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Assignments) != 0 {
+		t.Errorf("Assignments = %v, want none", plan.Assignments)
+	}
+	if plan.Missing["m1"] != 1 {
+		t.Errorf("Missing = %v, want m1: 1", plan.Missing)
+	}
+}
@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDialTimeout = 5 * time.Second
+)
+
+type RespError string
+
+func (e RespError) Error() string {
+	return string(e)
+}
+
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+	proto  int
+}
+
+type DialOptions struct {
+	// User is the ACL user to authenticate as, the default user when empty.
+	User     string
+	Password string
+	// TLS enables TLS with the given config, its ServerName defaults to the dialed host.
+	TLS *tls.Config
+}
+
+func Dial(ctx context.Context, host string, port int, password string) (*Conn, error) {
+	return DialWith(ctx, host, port, DialOptions{Password: password})
+}
+
+func DialWith(ctx context.Context, host string, port int, opts DialOptions) (*Conn, error) {
+	dialer := net.Dialer{Timeout: DefaultDialTimeout}
+	nc, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s:%d: %w", host, port, err)
+	}
+
+	if opts.TLS != nil {
+		config := opts.TLS.Clone()
+		if config.ServerName == "" {
+			config.ServerName = host
+		}
+
+		tlsConn := tls.Client(nc, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("failed to tls handshake with %s:%d: %w", host, port, err)
+		}
+		nc = tlsConn
+	}
+
+	conn := &Conn{
+		conn:   nc,
+		reader: bufio.NewReader(nc),
+		writer: bufio.NewWriter(nc),
+		proto:  2,
+	}
+
+	if err := conn.handshake(ctx, opts.User, opts.Password); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to handshake with %s:%d: %w", host, port, err)
+	}
+
+	return conn, nil
+}
+
+func (c *Conn) handshake(ctx context.Context, user, password string) error {
+	if user == "" {
+		user = "default"
+	}
+
+	args := []string{"HELLO", "3"}
+	if password != "" {
+		args = append(args, "AUTH", user, password)
+	}
+
+	_, err := c.Do(ctx, args...)
+	if err == nil {
+		c.proto = 3
+		return nil
+	}
+
+	var respErr RespError
+	if !errors.As(err, &respErr) {
+		return err
+	}
+
+	// servers older than redis 6 do not know HELLO, fall back to RESP2 and plain AUTH
+	if password == "" {
+		return nil
+	}
+
+	args = []string{"AUTH", password}
+	if user != "default" {
+		args = []string{"AUTH", user, password}
+	}
+	if _, err := c.Do(ctx, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Conn) Proto() int {
+	return c.proto
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) Do(ctx context.Context, args ...string) (any, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetDeadline(deadline)
+	} else {
+		c.conn.SetDeadline(time.Time{})
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		c.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := writeCommand(c.writer, args); err != nil {
+		return nil, fmt.Errorf("failed to write command %s: %w", args[0], err)
+	}
+
+	reply, err := readReply(c.reader)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+
+	if respErr, ok := reply.(RespError); ok {
+		return nil, respErr
+	}
+
+	return reply, nil
+}
+
+func writeCommand(w *bufio.Writer, args []string) error {
+	w.WriteString("*")
+	w.WriteString(strconv.Itoa(len(args)))
+	w.WriteString("\r\n")
+	for _, arg := range args {
+		w.WriteString("$")
+		w.WriteString(strconv.Itoa(len(arg)))
+		w.WriteString("\r\n")
+		w.WriteString(arg)
+		w.WriteString("\r\n")
+	}
+	return w.Flush()
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("malformed line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+func readBlob(r *bufio.Reader, size string) (string, bool, error) {
+	n, err := strconv.Atoi(size)
+	if err != nil {
+		return "", false, fmt.Errorf("malformed length %q: %w", size, err)
+	}
+	if n < 0 {
+		return "", false, nil
+	}
+
+	buf := make([]byte, n+2)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", false, err
+	}
+
+	return string(buf[:n]), true, nil
+}
+
+func readAggregate(r *bufio.Reader, size string) ([]any, bool, error) {
+	n, err := strconv.Atoi(size)
+	if err != nil {
+		return nil, false, fmt.Errorf("malformed length %q: %w", size, err)
+	}
+	if n < 0 {
+		return nil, false, nil
+	}
+
+	values := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		value, err := readReply(r)
+		if err != nil {
+			return nil, false, err
+		}
+		values = append(values, value)
+	}
+
+	return values, true, nil
+}
+
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("empty reply")
+	}
+
+	body := line[1:]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return RespError(body), nil
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		value, ok, err := readBlob(r, body)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return value, nil
+	case '*', '~', '>':
+		values, ok, err := readAggregate(r, body)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return values, nil
+	case '_':
+		return nil, nil
+	case '#':
+		return body == "t", nil
+	case ',':
+		return strconv.ParseFloat(body, 64)
+	case '(':
+		return body, nil
+	case '!':
+		value, _, err := readBlob(r, body)
+		if err != nil {
+			return nil, err
+		}
+		return RespError(value), nil
+	case '=':
+		value, _, err := readBlob(r, body)
+		if err != nil {
+			return nil, err
+		}
+		// verbatim strings are prefixed with a three letter format and a colon, e.g. "txt:"
+		if len(value) >= 4 && value[3] == ':' {
+			value = value[4:]
+		}
+		return value, nil
+	case '%', '|':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed length %q: %w", body, err)
+		}
+		values, _, err := readAggregate(r, strconv.Itoa(n*2))
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]any, len(values)/2)
+		for i := 0; i+1 < len(values); i += 2 {
+			m[fmt.Sprint(values[i])] = values[i+1]
+		}
+		if line[0] == '|' {
+			// attributes decorate the reply that follows them, they are not a reply on their own
+			return readReply(r)
+		}
+		return m, nil
+	}
+
+	return nil, fmt.Errorf("unknown reply type %q", line[0])
+}
+
+func ReplyString(reply any) (string, error) {
+	switch v := reply.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("unexpected reply type %T", reply)
+}
+
+func ReplyInt(reply any) (int64, error) {
+	switch v := reply.(type) {
+	case int64:
+		return v, nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	}
+	return 0, fmt.Errorf("unexpected reply type %T", reply)
+}
+
+func ReplyStrings(reply any) ([]string, error) {
+	values, ok := reply.([]any)
+	if !ok {
+		if reply == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unexpected reply type %T", reply)
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		s, err := ReplyString(value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+
+	return result, nil
+}
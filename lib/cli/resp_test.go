@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  any
+	}{
+		{"simple string", "+OK\r\n", "OK"},
+		{"error", "-ERR unknown command\r\n", RespError("ERR unknown command")},
+		{"integer", ":-42\r\n", int64(-42)},
+		{"bulk string", "$5\r\nhello\r\n", "hello"},
+		{"empty bulk string", "$0\r\n\r\n", ""},
+		{"null bulk string", "$-1\r\n", nil},
+		{"array", "*2\r\n$3\r\nfoo\r\n:1\r\n", []any{"foo", int64(1)}},
+		{"empty array", "*0\r\n", []any{}},
+		{"null array", "*-1\r\n", nil},
+		{"nested array", "*2\r\n*1\r\n+a\r\n$-1\r\n", []any{[]any{"a"}, nil}},
+		{"null", "_\r\n", nil},
+		{"boolean true", "#t\r\n", true},
+		{"boolean false", "#f\r\n", false},
+		{"double", ",3.25\r\n", 3.25},
+		{"big number", "(3492890328409238509324850943850943825024385\r\n", "3492890328409238509324850943850943825024385"},
+		{"blob error", "!21\r\nSYNTAX invalid syntax\r\n", RespError("SYNTAX invalid syntax")},
+		{"verbatim string", "=15\r\ntxt:Some string\r\n", "Some string"},
+		{"map", "%2\r\n+first\r\n:1\r\n$6\r\nsecond\r\n#t\r\n", map[string]any{"first": int64(1), "second": true}},
+		{"set", "~2\r\n+a\r\n+b\r\n", []any{"a", "b"}},
+		{"push", ">2\r\n+message\r\n+hello\r\n", []any{"message", "hello"}},
+		{"attribute", "|1\r\n+ttl\r\n:3600\r\n$3\r\nfoo\r\n", "foo"},
+		{"attribute in array", "*2\r\n|1\r\n+key-popularity\r\n,0.19\r\n:2039\r\n:9543892\r\n", []any{int64(2039), int64(9543892)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.input))
+			got, err := readReply(r)
+			if err != nil {
+				t.Fatalf("readReply() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readReply() = %#v, want %#v", got, tt.want)
+			}
+			if r.Buffered() != 0 {
+				t.Errorf("readReply() left %d bytes unread", r.Buffered())
+			}
+		})
+	}
+}
+
+func TestReadReplyMalformed(t *testing.T) {
+	for _, input := range []string{"", "\r\n", "+OK\n", "?what\r\n", ":abc\r\n", "$x\r\n", "$5\r\nhi\r\n", "*2\r\n+a\r\n"} {
+		if got, err := readReply(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Errorf("readReply(%q) = %#v, want error", input, got)
+		}
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		replies  []string
+		want     [][]string
+		proto    int
+		err      bool
+	}{
+		{
+			name:     "hello",
+			password: "secret",
+			replies:  []string{"%1\r\n+proto\r\n:3\r\n"},
+			want:     [][]string{{"HELLO", "3", "AUTH", "default", "secret"}},
+			proto:    3,
+		},
+		{
+			name:     "hello rejected falls back to auth",
+			password: "secret",
+			replies:  []string{"-ERR unknown command 'HELLO'\r\n", "+OK\r\n"},
+			want:     [][]string{{"HELLO", "3", "AUTH", "default", "secret"}, {"AUTH", "secret"}},
+			proto:    2,
+		},
+		{
+			name:     "hello rejected falls back to acl auth",
+			user:     "operator",
+			password: "secret",
+			replies:  []string{"-ERR unknown command 'HELLO'\r\n", "+OK\r\n"},
+			want:     [][]string{{"HELLO", "3", "AUTH", "operator", "secret"}, {"AUTH", "operator", "secret"}},
+			proto:    2,
+		},
+		{
+			name:    "hello rejected without password",
+			replies: []string{"-ERR unknown command 'HELLO'\r\n"},
+			want:    [][]string{{"HELLO", "3"}},
+			proto:   2,
+		},
+		{
+			name:     "auth rejected",
+			password: "wrong",
+			replies:  []string{"-ERR unknown command 'HELLO'\r\n", "-WRONGPASS invalid username-password pair or user is disabled.\r\n"},
+			want:     [][]string{{"HELLO", "3", "AUTH", "default", "wrong"}, {"AUTH", "wrong"}},
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+
+			received := make(chan [][]string, 1)
+			go func() {
+				defer server.Close()
+
+				r := bufio.NewReader(server)
+				commands := make([][]string, 0)
+				for _, reply := range tt.replies {
+					request, err := readReply(r)
+					if err != nil {
+						break
+					}
+					args, _ := ReplyStrings(request)
+					commands = append(commands, args)
+					server.Write([]byte(reply))
+				}
+				received <- commands
+			}()
+
+			conn := &Conn{
+				conn:   client,
+				reader: bufio.NewReader(client),
+				writer: bufio.NewWriter(client),
+				proto:  2,
+			}
+			err := conn.handshake(context.Background(), tt.user, tt.password)
+			if (err != nil) != tt.err {
+				t.Fatalf("handshake() error = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && conn.Proto() != tt.proto {
+				t.Errorf("Proto() = %d, want %d", conn.Proto(), tt.proto)
+			}
+
+			if got := <-received; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commands = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,534 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultScanCount   = 1000
+	DefaultScanTopKeys = 20
+)
+
+type ScanOptions struct {
+	// NodeIDs limits the scan to these masters, every master is scanned when empty.
+	NodeIDs []string `json:"node_ids,omitempty"`
+	// Parallelism is how many masters are scanned at once, DefaultSnapshotParallelism when 0.
+	Parallelism int `json:"parallelism,omitempty"`
+	// Count is the COUNT hint of every SCAN call, DefaultScanCount when 0.
+	Count int `json:"count,omitempty"`
+	// SampleRate inspects the type, memory and TTL of one key out of SampleRate, every key when 0 or 1.
+	SampleRate int `json:"sample_rate,omitempty"`
+	// TopKeys is how many of the biggest and hottest keys are reported, DefaultScanTopKeys when 0.
+	TopKeys int `json:"top_keys,omitempty"`
+}
+
+type KeySample struct {
+	Key    string `json:"key"`
+	NodeID string `json:"node_id"`
+	Slot   int    `json:"slot"`
+	Type   string `json:"type"`
+	Memory int64  `json:"memory"`
+	// TTL is in milliseconds, -1 for keys without expiry.
+	TTL int64 `json:"ttl"`
+	// Freq is the access frequency reported by OBJECT FREQ, only known on nodes with an LFU maxmemory-policy.
+	Freq int64 `json:"freq,omitempty"`
+}
+
+type SlotUsage struct {
+	Slot   int   `json:"slot"`
+	Keys   int64 `json:"keys"`
+	Memory int64 `json:"memory"`
+}
+
+type NodeKeyspace struct {
+	NodeID  string `json:"node_id"`
+	Address string `json:"address"`
+	Keys    int64  `json:"keys"`
+	Sampled int64  `json:"sampled"`
+	Memory  int64  `json:"memory"`
+	Error   string `json:"error,omitempty"`
+}
+
+// KeyspaceReport summarizes the keys of the scanned masters.
+// Memory is estimated from the sampled keys, key counts are exact.
+type KeyspaceReport struct {
+	Time        time.Time        `json:"time"`
+	SampleRate  int              `json:"sample_rate"`
+	Keys        int64            `json:"keys"`
+	Sampled     int64            `json:"sampled"`
+	Memory      int64            `json:"memory"`
+	Expiring    int64            `json:"expiring"`
+	Types       map[string]int64 `json:"types"`
+	Nodes       []NodeKeyspace   `json:"nodes"`
+	Slots       []SlotUsage      `json:"slots"`
+	BiggestKeys []KeySample      `json:"biggest_keys"`
+	HotKeys     []KeySample      `json:"hot_keys,omitempty"`
+}
+
+// SlotsOf returns the usage of the given slots, e.g. the slots of a node about to be drained.
+func (r *KeyspaceReport) SlotsOf(slots SlotSet) []SlotUsage {
+	usage := make([]SlotUsage, 0)
+	for _, u := range r.Slots {
+		if slots.Contains(u.Slot) {
+			usage = append(usage, u)
+		}
+	}
+	return usage
+}
+
+type nodeScan struct {
+	keyspace NodeKeyspace
+	expiring int64
+	types    map[string]int64
+	slots    map[int]*SlotUsage
+	biggest  []KeySample
+	hottest  []KeySample
+}
+
+// topKeys keeps the n greatest samples of keys by value.
+func topKeys(keys []KeySample, n int, value func(KeySample) int64) []KeySample {
+	slices.SortStableFunc(keys, func(a, b KeySample) int {
+		if va, vb := value(a), value(b); va != vb {
+			if va > vb {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+func keyMemory(k KeySample) int64 { return k.Memory }
+
+func keyFreq(k KeySample) int64 { return k.Freq }
+
+// ScanKeyspace runs SCAN on the masters of the cluster, samples the keys it finds and reports the biggest keys,
+// the keys and memory of every slot and, on masters evicting by LFU, the hottest keys.
+// A master that fails to be scanned is reported with its error.
+func (cli *CLI) ScanKeyspace(ctx context.Context, host string, port int, opts ScanOptions) (*KeyspaceReport, error) {
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = DefaultSnapshotParallelism
+	}
+	if opts.Count <= 0 {
+		opts.Count = DefaultScanCount
+	}
+	if opts.SampleRate <= 0 {
+		opts.SampleRate = 1
+	}
+	if opts.TopKeys <= 0 {
+		opts.TopKeys = DefaultScanTopKeys
+	}
+
+	masters := make([]*ClusterNode, 0)
+	for _, node := range nodes {
+		if !node.IsMaster() || node.IsFailing() {
+			continue
+		}
+		if len(opts.NodeIDs) > 0 && !slices.Contains(opts.NodeIDs, node.ID) {
+			continue
+		}
+		masters = append(masters, node)
+	}
+	for _, id := range opts.NodeIDs {
+		if !slices.ContainsFunc(masters, func(n *ClusterNode) bool { return n.ID == id }) {
+			return nil, fmt.Errorf("master %s: %w", id, ErrNodeNotFound)
+		}
+	}
+
+	log.Info().Str("host", host).Int("port", port).Int("masters", len(masters)).Int("sampleRate", opts.SampleRate).Msg("scan keyspace")
+
+	// progress functions are not expected to be called concurrently
+	progressLock := sync.Mutex{}
+	done := 0
+
+	scans := make([]*nodeScan, len(masters))
+	sem := make(chan struct{}, opts.Parallelism)
+	wg := sync.WaitGroup{}
+	for i, node := range masters {
+		scans[i] = &nodeScan{
+			keyspace: NodeKeyspace{NodeID: node.ID},
+			types:    make(map[string]int64),
+			slots:    make(map[int]*SlotUsage),
+		}
+
+		nodeHost, nodePort, err := nodeAddress(node, host)
+		if err != nil {
+			scans[i].keyspace.Error = err.Error()
+			continue
+		}
+		scans[i].keyspace.Address = joinAddress(nodeHost, nodePort)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				scans[i].keyspace.Error = ctx.Err().Error()
+				return
+			}
+			defer func() { <-sem }()
+
+			if err := cli.scanNode(ctx, nodeHost, nodePort, scans[i], opts); err != nil {
+				scans[i].keyspace.Error = err.Error()
+			}
+
+			progressLock.Lock()
+			done++
+			ReportProgress(ctx, Progress{
+				Operation: "scan",
+				Slot:      -1,
+				Source:    scans[i].keyspace.Address,
+				Keys:      int(scans[i].keyspace.Keys),
+				Done:      done,
+				Total:     len(masters),
+			})
+			progressLock.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &KeyspaceReport{
+		Time:        time.Now(),
+		SampleRate:  opts.SampleRate,
+		Types:       make(map[string]int64),
+		Nodes:       make([]NodeKeyspace, 0, len(scans)),
+		Slots:       make([]SlotUsage, 0),
+		BiggestKeys: make([]KeySample, 0),
+	}
+	for _, scan := range scans {
+		report.Nodes = append(report.Nodes, scan.keyspace)
+		report.Keys += scan.keyspace.Keys
+		report.Sampled += scan.keyspace.Sampled
+		report.Memory += scan.keyspace.Memory
+		report.Expiring += scan.expiring
+		for t, n := range scan.types {
+			report.Types[t] += n
+		}
+		for _, usage := range scan.slots {
+			report.Slots = append(report.Slots, *usage)
+		}
+		report.BiggestKeys = append(report.BiggestKeys, scan.biggest...)
+		report.HotKeys = append(report.HotKeys, scan.hottest...)
+	}
+	slices.SortFunc(report.Slots, func(a, b SlotUsage) int {
+		return a.Slot - b.Slot
+	})
+	report.BiggestKeys = topKeys(report.BiggestKeys, opts.TopKeys, keyMemory)
+	report.HotKeys = topKeys(report.HotKeys, opts.TopKeys, keyFreq)
+
+	log.Info().Int64("keys", report.Keys).Int64("sampled", report.Sampled).Int64("memory", report.Memory).Msg("finish scan keyspace")
+
+	return report, nil
+}
+
+func (cli *CLI) scanNode(ctx context.Context, host string, port int, scan *nodeScan, opts ScanOptions) error {
+	conn, err := cli.dial(ctx, host, port)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do(ctx, "INFO", "memory")
+	if err != nil {
+		return fmt.Errorf("failed to get info: %w", err)
+	}
+	resp, err := ReplyString(reply)
+	if err != nil {
+		return fmt.Errorf("failed to read info: %w", err)
+	}
+	lfu := strings.Contains(parseNodeInfo([]byte(resp)).Memory.MaxMemoryPolicy, "lfu")
+
+	count := strconv.Itoa(opts.Count)
+	cursor := "0"
+	for {
+		reply, err := conn.Do(ctx, "SCAN", cursor, "COUNT", count)
+		if err != nil {
+			return fmt.Errorf("failed to scan: %w", err)
+		}
+		values, ok := reply.([]any)
+		if !ok || len(values) != 2 {
+			return fmt.Errorf("unexpected scan reply %v", reply)
+		}
+		if cursor, err = ReplyString(values[0]); err != nil {
+			return fmt.Errorf("failed to read scan cursor: %w", err)
+		}
+		keys, err := ReplyStrings(values[1])
+		if err != nil {
+			return fmt.Errorf("failed to read scan keys: %w", err)
+		}
+
+		for _, key := range keys {
+			slot := KeySlot(key)
+			usage, ok := scan.slots[slot]
+			if !ok {
+				usage = &SlotUsage{Slot: slot}
+				scan.slots[slot] = usage
+			}
+			usage.Keys++
+			scan.keyspace.Keys++
+
+			if scan.keyspace.Keys%int64(opts.SampleRate) != 0 {
+				continue
+			}
+
+			sample, err := sampleKey(ctx, conn, key, lfu)
+			if err != nil {
+				return err
+			}
+			if sample == nil {
+				// the key expired or was deleted between SCAN and sampling
+				continue
+			}
+			sample.NodeID = scan.keyspace.NodeID
+			sample.Slot = slot
+
+			scan.keyspace.Sampled++
+			scan.types[sample.Type]++
+			if sample.TTL >= 0 {
+				scan.expiring++
+			}
+			estimated := sample.Memory * int64(opts.SampleRate)
+			usage.Memory += estimated
+			scan.keyspace.Memory += estimated
+
+			scan.biggest = append(scan.biggest, *sample)
+			if len(scan.biggest) > 2*opts.TopKeys {
+				scan.biggest = topKeys(scan.biggest, opts.TopKeys, keyMemory)
+			}
+			if lfu {
+				scan.hottest = append(scan.hottest, *sample)
+				if len(scan.hottest) > 2*opts.TopKeys {
+					scan.hottest = topKeys(scan.hottest, opts.TopKeys, keyFreq)
+				}
+			}
+		}
+
+		if cursor == "0" {
+			break
+		}
+	}
+
+	scan.biggest = topKeys(scan.biggest, opts.TopKeys, keyMemory)
+	scan.hottest = topKeys(scan.hottest, opts.TopKeys, keyFreq)
+
+	return nil
+}
+
+// sampleKey reads the type, memory usage, TTL and, when lfu is set, the access frequency of key.
+// It returns nil when the key no longer exists.
+func sampleKey(ctx context.Context, conn *Conn, key string, lfu bool) (*KeySample, error) {
+	reply, err := conn.Do(ctx, "TYPE", key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type of %s: %w", key, err)
+	}
+	keyType, err := ReplyString(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read type of %s: %w", key, err)
+	}
+	if keyType == "none" {
+		return nil, nil
+	}
+
+	reply, err = conn.Do(ctx, "MEMORY", "USAGE", key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory usage of %s: %w", key, err)
+	}
+	if reply == nil {
+		return nil, nil
+	}
+	memory, err := ReplyInt(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory usage of %s: %w", key, err)
+	}
+
+	reply, err = conn.Do(ctx, "PTTL", key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ttl of %s: %w", key, err)
+	}
+	ttl, err := ReplyInt(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ttl of %s: %w", key, err)
+	}
+	if ttl == -2 {
+		return nil, nil
+	}
+
+	sample := &KeySample{
+		Key:    key,
+		Type:   keyType,
+		Memory: memory,
+		TTL:    ttl,
+	}
+
+	if lfu {
+		reply, err = conn.Do(ctx, "OBJECT", "FREQ", key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get frequency of %s: %w", key, err)
+		}
+		if sample.Freq, err = ReplyInt(reply); err != nil {
+			return nil, fmt.Errorf("failed to read frequency of %s: %w", key, err)
+		}
+	}
+
+	return sample, nil
+}
+
+// DefaultSlotSamples is how many keys of a slot MeasureSlots samples to estimate the memory of the slot.
+const DefaultSlotSamples = 10
+
+// MeasureSlots counts the keys of every slot owned by a master with CLUSTER COUNTKEYSINSLOT and estimates the memory
+// of the slot from the MEMORY USAGE of up to samples of its keys. Only slots holding keys are returned.
+func (cli *CLI) MeasureSlots(ctx context.Context, host string, port int, samples int) ([]SlotUsage, error) {
+	nodes, err := cli.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	if samples <= 0 {
+		samples = DefaultSlotSamples
+	}
+
+	masters := make([]*ClusterNode, 0)
+	for _, node := range nodes {
+		if node.IsMaster() && !node.IsFailing() && !node.Slots.IsEmpty() {
+			masters = append(masters, node)
+		}
+	}
+
+	log.Info().Str("host", host).Int("port", port).Int("masters", len(masters)).Msg("measure slots")
+
+	usages := make([][]SlotUsage, len(masters))
+	errs := make([]error, len(masters))
+	sem := make(chan struct{}, DefaultSnapshotParallelism)
+	wg := sync.WaitGroup{}
+	for i, node := range masters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			usages[i], errs[i] = cli.measureNode(ctx, node, host, samples)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	result := make([]SlotUsage, 0)
+	for _, usage := range usages {
+		result = append(result, usage...)
+	}
+	slices.SortFunc(result, func(a, b SlotUsage) int {
+		return a.Slot - b.Slot
+	})
+
+	log.Info().Int("slots", len(result)).Msg("finish measure slots")
+
+	return result, nil
+}
+
+func (cli *CLI) measureNode(ctx context.Context, node *ClusterNode, fallbackHost string, samples int) ([]SlotUsage, error) {
+	host, port, err := nodeAddress(node, fallbackHost)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := cli.dial(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	usage := make([]SlotUsage, 0)
+	for _, r := range node.Slots {
+		for slot := r.Start; slot <= r.End; slot++ {
+			reply, err := conn.Do(ctx, "CLUSTER", "COUNTKEYSINSLOT", strconv.Itoa(slot))
+			if err != nil {
+				return nil, fmt.Errorf("failed to count keys in slot %d of %s: %w", slot, node.ID, err)
+			}
+			keys, err := ReplyInt(reply)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read key count of slot %d: %w", slot, err)
+			}
+			if keys == 0 {
+				continue
+			}
+
+			reply, err = conn.Do(ctx, "CLUSTER", "GETKEYSINSLOT", strconv.Itoa(slot), strconv.Itoa(samples))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get keys in slot %d of %s: %w", slot, node.ID, err)
+			}
+			names, err := ReplyStrings(reply)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read keys of slot %d: %w", slot, err)
+			}
+
+			sampled, memory := int64(0), int64(0)
+			for _, name := range names {
+				reply, err := conn.Do(ctx, "MEMORY", "USAGE", name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get memory usage of %s: %w", name, err)
+				}
+				if reply == nil {
+					continue
+				}
+				bytes, err := ReplyInt(reply)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read memory usage of %s: %w", name, err)
+				}
+				sampled++
+				memory += bytes
+			}
+
+			u := SlotUsage{Slot: slot, Keys: keys}
+			if sampled > 0 {
+				u.Memory = memory * keys / sampled
+			}
+			usage = append(usage, u)
+		}
+	}
+
+	return usage, nil
+}
+
+// SlotBytes maps the slots of usage to their memory, as RebalanceOptions.SlotBytes expects.
+func SlotBytes(usage []SlotUsage) map[int]int64 {
+	bytes := make(map[int]int64, len(usage))
+	for _, u := range usage {
+		bytes[u.Slot] = u.Memory
+	}
+	return bytes
+}
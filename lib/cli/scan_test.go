@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTopKeys(t *testing.T) {
+	keys := []KeySample{
+		{Key: "a", Memory: 10, Freq: 3},
+		{Key: "b", Memory: 30, Freq: 1},
+		{Key: "c", Memory: 20, Freq: 3},
+		{Key: "d", Memory: 30, Freq: 2},
+	}
+
+	names := func(keys []KeySample) []string {
+		result := make([]string, 0, len(keys))
+		for _, k := range keys {
+			result = append(result, k.Key)
+		}
+		return result
+	}
+
+	if got := names(topKeys(slices.Clone(keys), 3, keyMemory)); !slices.Equal(got, []string{"b", "d", "c"}) {
+		t.Errorf("topKeys(memory) = %v, want [b d c]", got)
+	}
+	if got := names(topKeys(slices.Clone(keys), 2, keyFreq)); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("topKeys(freq) = %v, want [a c]", got)
+	}
+	if got := topKeys(slices.Clone(keys), 10, keyMemory); len(got) != 4 {
+		t.Errorf("topKeys() kept %d keys, want 4", len(got))
+	}
+}
+
+func TestKeyspaceReportSlotsOf(t *testing.T) {
+	report := &KeyspaceReport{
+		Slots: []SlotUsage{
+			{Slot: 1, Keys: 2, Memory: 100},
+			{Slot: 5000, Keys: 1, Memory: 50},
+			{Slot: 9000, Keys: 4, Memory: 400},
+		},
+	}
+
+	usage := report.SlotsOf(NewSlotSet(SlotRange{Start: 0, End: 5460}))
+	if len(usage) != 2 || usage[0].Slot != 1 || usage[1].Slot != 5000 {
+		t.Errorf("SlotsOf() = %+v, want slots 1 and 5000", usage)
+	}
+}
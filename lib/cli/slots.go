@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type SlotRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func (r SlotRange) Count() int {
+	return r.End - r.Start + 1
+}
+
+func (r SlotRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return strconv.Itoa(r.Start) + "-" + strconv.Itoa(r.End)
+}
+
+// SlotSet is a sorted list of non-overlapping, non-adjacent slot ranges.
+type SlotSet []SlotRange
+
+func NewSlotSet(ranges ...SlotRange) SlotSet {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b SlotRange) int {
+		return a.Start - b.Start
+	})
+
+	set := make(SlotSet, 0, len(sorted))
+	for _, r := range sorted {
+		if r.Start > r.End {
+			continue
+		}
+		if n := len(set); n > 0 && r.Start <= set[n-1].End+1 {
+			set[n-1].End = max(set[n-1].End, r.End)
+			continue
+		}
+		set = append(set, r)
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+
+	return set
+}
+
+func SlotSetOf(slots ...int) SlotSet {
+	ranges := make([]SlotRange, 0, len(slots))
+	for _, slot := range slots {
+		ranges = append(ranges, SlotRange{Start: slot, End: slot})
+	}
+	return NewSlotSet(ranges...)
+}
+
+func (s SlotSet) Count() int {
+	count := 0
+	for _, r := range s {
+		count += r.Count()
+	}
+	return count
+}
+
+func (s SlotSet) IsEmpty() bool {
+	return len(s) == 0
+}
+
+func (s SlotSet) Contains(slot int) bool {
+	idx, found := slices.BinarySearchFunc(s, slot, func(r SlotRange, slot int) int {
+		switch {
+		case r.End < slot:
+			return -1
+		case r.Start > slot:
+			return 1
+		}
+		return 0
+	})
+	return found && idx < len(s)
+}
+
+func (s SlotSet) IsContiguous() bool {
+	return len(s) == 1
+}
+
+func (s SlotSet) Union(other SlotSet) SlotSet {
+	return NewSlotSet(append(slices.Clone(s), other...)...)
+}
+
+func (s SlotSet) Subtract(other SlotSet) SlotSet {
+	result := make([]SlotRange, 0, len(s))
+	for _, r := range s {
+		pieces := []SlotRange{r}
+		for _, o := range other {
+			next := make([]SlotRange, 0, len(pieces)+1)
+			for _, p := range pieces {
+				if o.End < p.Start || o.Start > p.End {
+					next = append(next, p)
+					continue
+				}
+				if o.Start > p.Start {
+					next = append(next, SlotRange{Start: p.Start, End: o.Start - 1})
+				}
+				if o.End < p.End {
+					next = append(next, SlotRange{Start: o.End + 1, End: p.End})
+				}
+			}
+			pieces = next
+		}
+		result = append(result, pieces...)
+	}
+	return NewSlotSet(result...)
+}
+
+func (s SlotSet) Intersect(other SlotSet) SlotSet {
+	return s.Subtract(s.Subtract(other))
+}
+
+// Head returns the first n slots of the set.
+func (s SlotSet) Head(n int) SlotSet {
+	result := make([]SlotRange, 0)
+	for _, r := range s {
+		if n <= 0 {
+			break
+		}
+		take := min(n, r.Count())
+		result = append(result, SlotRange{Start: r.Start, End: r.Start + take - 1})
+		n -= take
+	}
+	return NewSlotSet(result...)
+}
+
+// Tail returns the last n slots of the set.
+func (s SlotSet) Tail(n int) SlotSet {
+	result := make([]SlotRange, 0)
+	for i := len(s) - 1; i >= 0 && n > 0; i-- {
+		take := min(n, s[i].Count())
+		result = append(result, SlotRange{Start: s[i].End - take + 1, End: s[i].End})
+		n -= take
+	}
+	return NewSlotSet(result...)
+}
+
+func (s SlotSet) Slots() []int {
+	slots := make([]int, 0, s.Count())
+	for _, r := range s {
+		for slot := r.Start; slot <= r.End; slot++ {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+func (s SlotSet) String() string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// SlotMap maps every slot of the cluster to the masters that claim it.
+type SlotMap struct {
+	owners [MaxSlotCount][]string
+}
+
+func NewSlotMap(nodes []*ClusterNode) *SlotMap {
+	m := &SlotMap{}
+	for _, node := range nodes {
+		if !node.IsMaster() {
+			continue
+		}
+		for _, r := range node.Slots {
+			for slot := r.Start; slot <= r.End; slot++ {
+				m.owners[slot] = append(m.owners[slot], node.ID)
+			}
+		}
+	}
+	return m
+}
+
+func (m *SlotMap) Owner(slot int) string {
+	if slot < 0 || slot >= MaxSlotCount || len(m.owners[slot]) == 0 {
+		return ""
+	}
+	return m.owners[slot][0]
+}
+
+func (m *SlotMap) Owners(slot int) []string {
+	if slot < 0 || slot >= MaxSlotCount {
+		return nil
+	}
+	return m.owners[slot]
+}
+
+func (m *SlotMap) Assigned() SlotSet {
+	return m.collect(func(owners []string) bool {
+		return len(owners) > 0
+	})
+}
+
+func (m *SlotMap) Unassigned() SlotSet {
+	return m.collect(func(owners []string) bool {
+		return len(owners) == 0
+	})
+}
+
+// Conflicts returns the slots claimed by more than one master.
+func (m *SlotMap) Conflicts() map[int][]string {
+	conflicts := make(map[int][]string)
+	for slot, owners := range m.owners {
+		if len(owners) > 1 {
+			conflicts[slot] = owners
+		}
+	}
+	return conflicts
+}
+
+func (m *SlotMap) NodeSlots() map[string]SlotSet {
+	ranges := make(map[string][]SlotRange)
+	for slot, owners := range m.owners {
+		for _, owner := range owners {
+			ranges[owner] = append(ranges[owner], SlotRange{Start: slot, End: slot})
+		}
+	}
+
+	result := make(map[string]SlotSet, len(ranges))
+	for owner, r := range ranges {
+		result[owner] = NewSlotSet(r...)
+	}
+	return result
+}
+
+func (m *SlotMap) collect(match func(owners []string) bool) SlotSet {
+	ranges := make([]SlotRange, 0)
+	for slot, owners := range m.owners {
+		if match(owners) {
+			ranges = append(ranges, SlotRange{Start: slot, End: slot})
+		}
+	}
+	return NewSlotSet(ranges...)
+}
+
+type slotOwnerJSON struct {
+	NodeID string  `json:"node_id"`
+	Slots  SlotSet `json:"slots"`
+}
+
+type slotConflictJSON struct {
+	Slot    int      `json:"slot"`
+	NodeIDs []string `json:"node_ids"`
+}
+
+func (m *SlotMap) MarshalJSON() ([]byte, error) {
+	owners := make([]slotOwnerJSON, 0)
+	for owner, slots := range m.NodeSlots() {
+		owners = append(owners, slotOwnerJSON{NodeID: owner, Slots: slots})
+	}
+	slices.SortFunc(owners, func(a, b slotOwnerJSON) int {
+		return a.Slots[0].Start - b.Slots[0].Start
+	})
+
+	conflicts := make([]slotConflictJSON, 0)
+	for slot, nodeIDs := range m.Conflicts() {
+		conflicts = append(conflicts, slotConflictJSON{Slot: slot, NodeIDs: nodeIDs})
+	}
+	slices.SortFunc(conflicts, func(a, b slotConflictJSON) int {
+		return a.Slot - b.Slot
+	})
+
+	unassigned := m.Unassigned()
+	if unassigned == nil {
+		unassigned = SlotSet{}
+	}
+
+	return json.Marshal(struct {
+		Owners     []slotOwnerJSON    `json:"owners"`
+		Unassigned SlotSet            `json:"unassigned"`
+		Conflicts  []slotConflictJSON `json:"conflicts"`
+	}{
+		Owners:     owners,
+		Unassigned: unassigned,
+		Conflicts:  conflicts,
+	})
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlotSet(t *testing.T) {
+	tests := []struct {
+		name string
+		got  SlotSet
+		want SlotSet
+	}{
+		{
+			name: "normalize merges overlapping and adjacent ranges",
+			got:  NewSlotSet(SlotRange{Start: 10, End: 20}, SlotRange{Start: 0, End: 5}, SlotRange{Start: 6, End: 9}, SlotRange{Start: 15, End: 30}),
+			want: SlotSet{{Start: 0, End: 30}},
+		},
+		{
+			name: "union",
+			got:  SlotSetOf(1, 2, 3).Union(SlotSetOf(7, 8)),
+			want: SlotSet{{Start: 1, End: 3}, {Start: 7, End: 8}},
+		},
+		{
+			name: "subtract splits ranges",
+			got:  SlotSet{{Start: 0, End: 100}}.Subtract(SlotSet{{Start: 10, End: 20}, {Start: 50, End: 50}}),
+			want: SlotSet{{Start: 0, End: 9}, {Start: 21, End: 49}, {Start: 51, End: 100}},
+		},
+		{
+			name: "subtract everything",
+			got:  SlotSet{{Start: 5, End: 10}}.Subtract(SlotSet{{Start: 0, End: 20}}),
+			want: nil,
+		},
+		{
+			name: "intersect",
+			got:  SlotSet{{Start: 0, End: 10}, {Start: 20, End: 30}}.Intersect(SlotSet{{Start: 5, End: 25}}),
+			want: SlotSet{{Start: 5, End: 10}, {Start: 20, End: 25}},
+		},
+		{
+			name: "head crosses ranges",
+			got:  SlotSet{{Start: 0, End: 2}, {Start: 10, End: 20}}.Head(5),
+			want: SlotSet{{Start: 0, End: 2}, {Start: 10, End: 11}},
+		},
+		{
+			name: "tail crosses ranges",
+			got:  SlotSet{{Start: 0, End: 10}, {Start: 20, End: 21}}.Tail(4),
+			want: SlotSet{{Start: 9, End: 10}, {Start: 20, End: 21}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlotSetQueries(t *testing.T) {
+	set := SlotSet{{Start: 0, End: 5460}, {Start: 10923, End: 10923}}
+
+	if got := set.Count(); got != 5462 {
+		t.Errorf("Count() = %d, want 5462", got)
+	}
+	if set.IsContiguous() {
+		t.Errorf("IsContiguous() = true, want false")
+	}
+	for slot, want := range map[int]bool{0: true, 5460: true, 5461: false, 10923: true, 16383: false} {
+		if got := set.Contains(slot); got != want {
+			t.Errorf("Contains(%d) = %v, want %v", slot, got, want)
+		}
+	}
+	if got := set.String(); got != "0-5460,10923" {
+		t.Errorf("String() = %q, want %q", got, "0-5460,10923")
+	}
+}
+
+func TestSlotMap(t *testing.T) {
+	nodes := []*ClusterNode{
+		{ID: "a", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 0, End: 8000}}},
+		{ID: "b", Flags: NewNodeFlags(FlagMaster), Slots: SlotSet{{Start: 8000, End: 16000}}},
+		{ID: "c", Flags: NewNodeFlags(FlagSlave), MasterID: "a"},
+	}
+
+	m := NewSlotMap(nodes)
+
+	if got, want := m.Unassigned(), (SlotSet{{Start: 16001, End: 16383}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unassigned() = %v, want %v", got, want)
+	}
+	if got, want := m.Conflicts(), map[int][]string{8000: {"a", "b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts() = %v, want %v", got, want)
+	}
+	if got := m.Owner(100); got != "a" {
+		t.Errorf("Owner(100) = %q, want %q", got, "a")
+	}
+	if got := m.Owner(16100); got != "" {
+		t.Errorf("Owner(16100) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type NodeRole string
+
+const (
+	RoleMaster  NodeRole = "master"
+	RoleReplica NodeRole = "replica"
+)
+
+type NodeSpec struct {
+	Address string   `json:"address" yaml:"address"`
+	Role    NodeRole `json:"role" yaml:"role"`
+	// Weight is the share of the slots of a master relative to the other masters, an omitted weight counts as 1.
+	Weight float64 `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// ClusterSpec describes the nodes a cluster should consist of, Reconcile converges the cluster to it.
+type ClusterSpec struct {
+	Nodes []NodeSpec `json:"nodes" yaml:"nodes"`
+	// Replicas is the number of replicas per master, when omitted the replica nodes are spread evenly over the masters.
+	Replicas int `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	// Threshold is passed to the rebalance, DefaultRebalanceThreshold when omitted.
+	Threshold float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+}
+
+// ParseClusterSpec reads a spec written in YAML or JSON.
+func ParseClusterSpec(data []byte) (*ClusterSpec, error) {
+	spec := &ClusterSpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster spec: %w", err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+func (s *ClusterSpec) Validate() error {
+	seen := make(map[string]bool, len(s.Nodes))
+	masters := 0
+	for _, node := range s.Nodes {
+		if _, _, err := splitAddress(node.Address); err != nil {
+			return fmt.Errorf("invalid cluster spec: %w", err)
+		}
+		if seen[node.Address] {
+			return fmt.Errorf("invalid cluster spec: node %s is listed twice", node.Address)
+		}
+		seen[node.Address] = true
+
+		switch node.Role {
+		case RoleMaster:
+			masters++
+		case RoleReplica:
+		default:
+			return fmt.Errorf("invalid cluster spec: unknown role %q of node %s", node.Role, node.Address)
+		}
+
+		if node.Weight < 0 {
+			return fmt.Errorf("invalid cluster spec: invalid weight %v of node %s", node.Weight, node.Address)
+		}
+	}
+
+	if masters == 0 {
+		return fmt.Errorf("invalid cluster spec: no master")
+	}
+	if s.Replicas < 0 {
+		return fmt.Errorf("invalid cluster spec: invalid replica count %d", s.Replicas)
+	}
+
+	return nil
+}
+
+func (s *ClusterSpec) node(address string) (NodeSpec, bool) {
+	for _, node := range s.Nodes {
+		if node.Address == address {
+			return node, true
+		}
+	}
+	return NodeSpec{}, false
+}
+
+func (s *ClusterSpec) isMaster(address string) bool {
+	node, ok := s.node(address)
+	return ok && node.Role == RoleMaster
+}
+
+func (s *ClusterSpec) weight(address string) float64 {
+	node, ok := s.node(address)
+	switch {
+	case !ok || node.Role != RoleMaster:
+		return 0
+	case node.Weight == 0:
+		return 1
+	}
+	return node.Weight
+}
+
+func (s *ClusterSpec) replicas() int {
+	if s.Replicas > 0 {
+		return s.Replicas
+	}
+
+	masters, replicas := 0, 0
+	for _, node := range s.Nodes {
+		if node.Role == RoleMaster {
+			masters++
+		} else {
+			replicas++
+		}
+	}
+	if replicas == 0 {
+		return 0
+	}
+	return max(1, replicas/masters)
+}
+
+func (s *ClusterSpec) threshold() float64 {
+	if s.Threshold > 0 {
+		return s.Threshold
+	}
+	return DefaultRebalanceThreshold
+}
+
+func splitAddress(address string) (string, int, error) {
+	idx := strings.LastIndex(address, ":")
+	if idx <= 0 {
+		return "", 0, fmt.Errorf("invalid address %q", address)
+	}
+
+	port, err := strconv.Atoi(address[idx+1:])
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port in address %q", address)
+	}
+
+	return address[:idx], port, nil
+}
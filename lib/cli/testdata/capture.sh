@@ -0,0 +1,62 @@
+#!/bin/sh
+# Captures the CLUSTER NODES output and nodes.conf of a real six node cluster into the fixtures of this directory.
+#
+#   ./capture.sh valkey/valkey:8.0 valkey8
+#   ./capture.sh valkey/valkey:7.2 valkey7
+#   ./capture.sh redis:7.2 redis7
+#
+# writes cluster_nodes_<name>.txt with an open slot and a node marked as failing, and nodes_conf_<name>.txt.
+set -eu
+
+image=$1
+name=$2
+dir=$(cd "$(dirname "$0")" && pwd)
+
+case $image in
+redis*) server=redis-server cli=redis-cli ;;
+*) server=valkey-server cli=valkey-cli ;;
+esac
+
+prefix=keycl-capture-$$
+docker network create "$prefix" >/dev/null
+cleanup() {
+	docker rm -f "$prefix-1" "$prefix-2" "$prefix-3" "$prefix-4" "$prefix-5" "$prefix-6" >/dev/null 2>&1 || true
+	docker network rm "$prefix" >/dev/null 2>&1 || true
+}
+trap cleanup EXIT
+
+addresses=""
+for i in 1 2 3 4 5 6; do
+	docker run -d --name "$prefix-$i" --network "$prefix" "$image" "$server" \
+		--port "700$i" --cluster-enabled yes --cluster-node-timeout 3000 \
+		--cluster-announce-hostname "node-$i" >/dev/null
+	ip=$(docker inspect -f '{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}' "$prefix-$i")
+	addresses="$addresses $ip:700$i"
+done
+sleep 1
+
+run() {
+	docker exec "$prefix-1" "$cli" -p 7001 "$@"
+}
+
+# shellcheck disable=SC2086
+docker exec "$prefix-1" "$cli" --cluster create $addresses --cluster-replicas 1 --cluster-yes >/dev/null
+until run cluster info | grep -q cluster_state:ok; do
+	sleep 1
+done
+
+# leave slot 0 half migrated to the second master
+target=$(docker exec "$prefix-2" "$cli" -p 7002 cluster myid)
+source=$(run cluster myid)
+docker exec "$prefix-2" "$cli" -p 7002 cluster setslot 0 importing "$source" >/dev/null
+run cluster setslot 0 migrating "$target" >/dev/null
+
+# a stopped master is marked fail by the others after the node timeout
+docker stop "$prefix-3" >/dev/null
+until run cluster nodes | grep -q ',fail '; do
+	sleep 1
+done
+sleep 5
+
+run cluster nodes >"$dir/cluster_nodes_$name.txt"
+docker exec "$prefix-1" cat /data/nodes.conf >"$dir/nodes_conf_$name.txt"
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultWaitTimeout is how long the wait helpers poll when the CLI was not given a timeout by WithWait.
+	DefaultWaitTimeout = 60 * time.Second
+	waitMinBackoff     = 100 * time.Millisecond
+	waitMaxBackoff     = 2 * time.Second
+)
+
+var ErrWaitTimeout = errors.New("cluster did not converge in time")
+
+// WithWait makes CreateCluster, AddNode and ReplicateNode block until every node sees the change, for at most timeout.
+func WithWait(timeout time.Duration) Option {
+	return func(cli *CLI) {
+		cli.wait = true
+		cli.waitTimeout = timeout
+	}
+}
+
+// WaitForClusterOK waits until every node of the cluster reports cluster_state:ok.
+func (cli *CLI) WaitForClusterOK(ctx context.Context, host string, port int) error {
+	return cli.waitFor(ctx, "cluster state ok", func(ctx context.Context) (string, error) {
+		nodes, err := cli.reachableNodes(ctx, host, port)
+		if err != nil {
+			return "", err
+		}
+
+		for _, node := range nodes {
+			reply, err := cli.do(ctx, node.Host, node.Port, "CLUSTER", "INFO")
+			if err != nil {
+				return "", err
+			}
+			resp, err := ReplyString(reply)
+			if err != nil {
+				return "", fmt.Errorf("failed to read cluster info: %w", err)
+			}
+
+			if info := parseClusterInfo([]byte(resp)); info.ClusterState != "ok" {
+				return fmt.Sprintf("%s is %s", node.Address(), info.ClusterState), nil
+			}
+		}
+
+		return "", nil
+	})
+}
+
+// WaitForNodeKnownByAll waits until every node of the cluster knows nodeID and finished the handshake with it.
+func (cli *CLI) WaitForNodeKnownByAll(ctx context.Context, host string, port int, nodeID string) error {
+	return cli.waitFor(ctx, "node "+nodeID+" known by all", func(ctx context.Context) (string, error) {
+		views, err := cli.clusterViews(ctx, host, port)
+		if err != nil {
+			return "", err
+		}
+
+		for address, view := range views {
+			known := false
+			for _, node := range view {
+				if node.ID == nodeID && !node.Flags.Has(FlagHandshake) && !node.Flags.Has(FlagNoAddr) {
+					known = true
+				}
+			}
+			if !known {
+				return fmt.Sprintf("%s does not know %s yet", address, nodeID), nil
+			}
+		}
+
+		return "", nil
+	})
+}
+
+// WaitForConfigConsistent waits until every node of the cluster agrees on which master owns which slots.
+func (cli *CLI) WaitForConfigConsistent(ctx context.Context, host string, port int) error {
+	return cli.waitFor(ctx, "config consistent", func(ctx context.Context) (string, error) {
+		views, err := cli.clusterViews(ctx, host, port)
+		if err != nil {
+			return "", err
+		}
+
+		signature := ""
+		for address, view := range views {
+			s := configSignature(view)
+			if signature == "" {
+				signature = s
+			}
+			if s != signature {
+				return fmt.Sprintf("%s disagrees about the slots", address), nil
+			}
+		}
+
+		return "", nil
+	})
+}
+
+// waitForReplica waits until every node of the cluster sees replicaID as a replica of masterID.
+func (cli *CLI) waitForReplica(ctx context.Context, host string, port int, replicaID, masterID string) error {
+	return cli.waitFor(ctx, "replica "+replicaID+" of "+masterID, func(ctx context.Context) (string, error) {
+		views, err := cli.clusterViews(ctx, host, port)
+		if err != nil {
+			return "", err
+		}
+
+		for address, view := range views {
+			replicating := false
+			for _, node := range view {
+				if node.ID == replicaID && node.IsReplica() && node.MasterID == masterID {
+					replicating = true
+				}
+			}
+			if !replicating {
+				return fmt.Sprintf("%s does not see %s as replica of %s yet", address, replicaID, masterID), nil
+			}
+		}
+
+		return "", nil
+	})
+}
+
+// waitFor polls check with exponential backoff until it reports nothing pending.
+// Errors of check are retried as well since nodes may refuse connections while they join.
+func (cli *CLI) waitFor(ctx context.Context, what string, check func(ctx context.Context) (string, error)) error {
+	timeout := cli.waitTimeout
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := waitMinBackoff
+	for {
+		pending, err := check(waitCtx)
+		if err == nil && pending == "" {
+			log.Info().Str("condition", what).Msg("cluster converged")
+			return nil
+		}
+		if err != nil {
+			pending = err.Error()
+		}
+		log.Debug().Str("condition", what).Str("pending", pending).Dur("delay", delay).Msg("waiting for cluster")
+
+		select {
+		case <-waitCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s: %s", ErrWaitTimeout, what, pending)
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, waitMaxBackoff)
+	}
+}
+
+// reachableNodes returns the nodes known to host:port that have an address and are not failing.
+func (cli *CLI) reachableNodes(ctx context.Context, host string, port int) ([]*ClusterNode, error) {
+	conn, err := cli.dial(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	nodes, err := clusterNodes(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	reachable := make([]*ClusterNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Flags.Has(FlagNoAddr) || node.IsFailing() {
+			continue
+		}
+		if node.Host == "" {
+			node.Host = host
+		}
+		reachable = append(reachable, node)
+	}
+
+	return reachable, nil
+}
+
+// clusterViews returns the CLUSTER NODES output of every reachable node keyed by its address.
+func (cli *CLI) clusterViews(ctx context.Context, host string, port int) (map[string][]*ClusterNode, error) {
+	nodes, err := cli.reachableNodes(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make(map[string][]*ClusterNode, len(nodes))
+	for _, node := range nodes {
+		conn, err := cli.dial(ctx, node.Host, node.Port)
+		if err != nil {
+			return nil, err
+		}
+
+		view, err := clusterNodes(ctx, conn)
+		conn.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster nodes of %s: %w", node.Address(), err)
+		}
+
+		views[node.Address()] = view
+	}
+
+	return views, nil
+}
+
+// myID returns the node ID of the node at host:port.
+func (cli *CLI) myID(ctx context.Context, host string, port int) (string, error) {
+	reply, err := cli.do(ctx, host, port, "CLUSTER", "MYID")
+	if err != nil {
+		return "", err
+	}
+	return ReplyString(reply)
+}
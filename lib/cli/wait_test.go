@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitFor(t *testing.T) {
+	c := New(Native, "", WithWait(time.Second))
+
+	calls := 0
+	err := c.waitFor(context.Background(), "third call", func(ctx context.Context) (string, error) {
+		calls++
+		switch calls {
+		case 1:
+			return "", errors.New("connection refused")
+		case 2:
+			return "not yet", nil
+		}
+		return "", nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("waitFor() = %v after %d calls, want nil after 3", err, calls)
+	}
+
+	c = New(Native, "", WithWait(150*time.Millisecond))
+	err = c.waitFor(context.Background(), "never", func(ctx context.Context) (string, error) {
+		return "not yet", nil
+	})
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("waitFor() = %v, want %v", err, ErrWaitTimeout)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = c.waitFor(ctx, "canceled", func(ctx context.Context) (string, error) {
+		return "not yet", nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("waitFor() = %v, want %v", err, context.Canceled)
+	}
+}
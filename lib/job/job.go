@@ -0,0 +1,173 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/store/queries"
+)
+
+type Kind string
+
+const (
+	KindMigrate   Kind = "migrate"
+	KindRebalance Kind = "rebalance"
+	KindReshard   Kind = "reshard"
+	KindScan      Kind = "scan"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+var (
+	ErrUnknownKind   = errors.New("unknown job kind")
+	ErrJobNotRunning = errors.New("job is not running")
+	ErrJobCanceled   = errors.New("job canceled")
+)
+
+type MigrateParams struct {
+	SourceID string      `json:"source_id"`
+	TargetID string      `json:"target_id"`
+	Slots    cli.SlotSet `json:"slots"`
+}
+
+type RebalanceParams struct {
+	Weights         map[string]float64 `json:"weights,omitempty"`
+	Threshold       float64            `json:"threshold"`
+	UseEmptyMasters bool               `json:"use_empty_masters"`
+	// ByData balances the measured bytes of the masters instead of their slot counts.
+	ByData  bool `json:"by_data"`
+	Samples int  `json:"samples,omitempty"`
+}
+
+type ReshardParams struct {
+	TargetID string `json:"target_id"`
+	Slots    int    `json:"slots"`
+	// SourceID is the node giving away slots, "all" takes them from every master.
+	SourceID string `json:"source_id"`
+}
+
+// ScanParams are the options of a keyspace scan, the report is stored as the result of the job.
+type ScanParams = cli.ScanOptions
+
+// Progress is stored while the job runs so an interrupted job can tell which slots were already moved.
+type Progress struct {
+	Done  int          `json:"done"`
+	Total int          `json:"total"`
+	Moved cli.SlotSet  `json:"moved,omitempty"`
+	Last  cli.Progress `json:"last"`
+}
+
+// apply counts one progress event of an operation. Slot migrations report one event per slot, valkey-cli driven
+// commands keep their own counters.
+func (p *Progress) apply(event cli.Progress) {
+	p.Last = event
+	if event.Slot >= 0 {
+		p.Moved = p.Moved.Union(cli.SlotSetOf(event.Slot))
+	}
+	if event.Operation == "migrate" {
+		p.Done++
+	} else {
+		p.Done, p.Total = event.Done, event.Total
+	}
+}
+
+type Job struct {
+	ID         int32           `json:"id"`
+	ClusterID  int32           `json:"cluster_id"`
+	Kind       Kind            `json:"kind"`
+	Status     Status          `json:"status"`
+	Params     json.RawMessage `json:"params"`
+	Progress   *Progress       `json:"progress,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+func (j *Job) IsFinished() bool {
+	switch j.Status {
+	case StatusSucceeded, StatusFailed, StatusCanceled:
+		return true
+	}
+	return false
+}
+
+func fromRow(row queries.Job) (*Job, error) {
+	job := &Job{
+		ID:        row.ID,
+		ClusterID: row.ClusterID,
+		Kind:      Kind(row.Kind),
+		Status:    Status(row.Status),
+		Params:    row.Params,
+		Result:    row.Result,
+		Error:     row.Error.String,
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+
+	if row.StartedAt.Valid {
+		job.StartedAt = &row.StartedAt.Time
+	}
+	if row.FinishedAt.Valid {
+		job.FinishedAt = &row.FinishedAt.Time
+	}
+
+	if len(row.Progress) > 0 {
+		job.Progress = &Progress{}
+		if err := json.Unmarshal(row.Progress, job.Progress); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal progress of job %d: %w", row.ID, err)
+		}
+	}
+
+	return job, nil
+}
+
+func validateParams(kind Kind, params []byte) error {
+	switch kind {
+	case KindMigrate:
+		p := MigrateParams{}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return fmt.Errorf("invalid migrate params: %w", err)
+		}
+		if p.SourceID == "" || p.TargetID == "" || p.Slots.IsEmpty() {
+			return fmt.Errorf("invalid migrate params: source_id, target_id and slots are required")
+		}
+	case KindReshard:
+		p := ReshardParams{}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return fmt.Errorf("invalid reshard params: %w", err)
+		}
+		if p.TargetID == "" || p.SourceID == "" || p.Slots <= 0 {
+			return fmt.Errorf("invalid reshard params: target_id, source_id and a positive slots are required")
+		}
+	case KindRebalance:
+		p := RebalanceParams{}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return fmt.Errorf("invalid rebalance params: %w", err)
+		}
+	case KindScan:
+		p := ScanParams{}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return fmt.Errorf("invalid scan params: %w", err)
+		}
+		if p.Parallelism < 0 || p.Count < 0 || p.SampleRate < 0 || p.TopKeys < 0 {
+			return fmt.Errorf("invalid scan params: parallelism, count, sample_rate and top_keys must not be negative")
+		}
+	default:
+		return fmt.Errorf("%s: %w", kind, ErrUnknownKind)
+	}
+	return nil
+}
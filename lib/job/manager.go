@@ -0,0 +1,370 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/store"
+)
+
+// DefaultProgressInterval is how often the progress of a running job is written to the database at most.
+const DefaultProgressInterval = time.Second
+
+type Manager struct {
+	ctx         context.Context
+	jobs        repository
+	newOperator cli.OperatorFactory
+	// progressInterval throttles the progress writes, a slot migration reports an event for every slot
+	progressInterval time.Duration
+
+	cancels     map[int32]context.CancelCauseFunc
+	cancelsLock sync.Mutex
+	wg          sync.WaitGroup
+
+	onProgress func(job *Job, progress cli.Progress)
+}
+
+// New returns a manager whose jobs live as long as ctx, jobs cut off by ctx are left running in the database and picked up by Resume.
+func New(ctx context.Context, store *store.Store, newOperator cli.OperatorFactory) *Manager {
+	return newManager(ctx, storeRepository{store: store}, newOperator)
+}
+
+func newManager(ctx context.Context, jobs repository, newOperator cli.OperatorFactory) *Manager {
+	return &Manager{
+		ctx:              ctx,
+		jobs:             jobs,
+		newOperator:      newOperator,
+		progressInterval: DefaultProgressInterval,
+		cancels:          make(map[int32]context.CancelCauseFunc),
+	}
+}
+
+// OnProgress registers fn to receive every progress event of every job, it must be called before jobs are started.
+func (m *Manager) OnProgress(fn func(job *Job, progress cli.Progress)) {
+	m.onProgress = fn
+}
+
+// Submit persists a new job for the cluster and starts it in the background.
+func (m *Manager) Submit(ctx context.Context, clusterName string, kind Kind, params any) (*Job, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	if err := validateParams(kind, data); err != nil {
+		return nil, err
+	}
+
+	job, err := m.jobs.create(ctx, clusterName, kind, data)
+	if err != nil {
+		return nil, err
+	}
+
+	m.start(job)
+
+	return job, nil
+}
+
+func (m *Manager) Get(ctx context.Context, id int32) (*Job, error) {
+	return m.jobs.get(ctx, id)
+}
+
+func (m *Manager) List(ctx context.Context, clusterName string, count int32) ([]*Job, error) {
+	return m.jobs.list(ctx, clusterName, count)
+}
+
+// Cancel stops a job running in this process, the job is recorded as canceled once its current step returns.
+func (m *Manager) Cancel(id int32) error {
+	m.cancelsLock.Lock()
+	defer m.cancelsLock.Unlock()
+
+	cancel, ok := m.cancels[id]
+	if !ok {
+		return fmt.Errorf("job %d: %w", id, ErrJobNotRunning)
+	}
+	cancel(ErrJobCanceled)
+
+	return nil
+}
+
+// Resume restarts the jobs that were pending or running when the previous process stopped.
+func (m *Manager) Resume(ctx context.Context) error {
+	jobs, err := m.jobs.unfinished(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		log.Info().Int32("jobID", job.ID).Str("kind", string(job.Kind)).Str("status", string(job.Status)).Msg("resume job")
+		m.start(job)
+	}
+
+	return nil
+}
+
+// Wait blocks until every job started by the manager returned.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+func (m *Manager) start(job *Job) {
+	ctx, cancel := context.WithCancelCause(m.ctx)
+
+	m.cancelsLock.Lock()
+	m.cancels[job.ID] = cancel
+	m.cancelsLock.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			m.cancelsLock.Lock()
+			delete(m.cancels, job.ID)
+			m.cancelsLock.Unlock()
+			cancel(nil)
+		}()
+
+		m.run(ctx, job)
+	}()
+}
+
+func (m *Manager) run(ctx context.Context, job *Job) {
+	// bookkeeping has to reach the database even after the job itself was canceled
+	storeCtx := context.WithoutCancel(ctx)
+
+	cluster, seeds, err := m.jobs.start(storeCtx, job)
+	if err != nil {
+		m.finish(storeCtx, job, nil, err)
+		return
+	}
+	operator := m.newOperator(cluster.Name, cluster.Password)
+
+	progress := job.Progress
+	if progress == nil {
+		progress = &Progress{}
+	}
+	resumed := job.Status == StatusRunning
+
+	// progress lost to a crash between two writes makes a resumed migrate check a few more slots against the slot map,
+	// and a resumed reshard move up to an interval of slots more than asked
+	saved := time.Time{}
+	ctx = cli.WithProgress(ctx, func(p cli.Progress) {
+		progress.apply(p)
+		if now := time.Now(); now.Sub(saved) >= m.progressInterval {
+			m.saveProgress(storeCtx, job.ID, progress)
+			saved = now
+		}
+
+		if m.onProgress != nil {
+			m.onProgress(job, p)
+		}
+	})
+
+	var result any
+	errs := make([]error, 0, len(seeds))
+	for _, seed := range seeds {
+		result, err = m.execute(ctx, operator, seed.Host, int(seed.Port), job, progress, resumed)
+		if err == nil {
+			break
+		}
+		errs = append(errs, fmt.Errorf("%s:%d: %w", seed.Host, seed.Port, err))
+
+		// only a seed that could not be reached is worth another one, any other failure would fail again
+		if ctx.Err() != nil || !cli.IsUnreachable(err) {
+			break
+		}
+		resumed = true
+	}
+	m.saveProgress(storeCtx, job.ID, progress)
+
+	if err == nil {
+		m.finish(storeCtx, job, result, nil)
+		return
+	}
+	m.finish(storeCtx, job, nil, errors.Join(errs...))
+}
+
+func (m *Manager) execute(ctx context.Context, operator cli.ClusterOperator, host string, port int, job *Job, progress *Progress, resumed bool) (any, error) {
+	switch job.Kind {
+	case KindMigrate:
+		params := MigrateParams{}
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, err
+		}
+
+		slots := params.Slots.Subtract(progress.Moved)
+		if resumed {
+			// the slot in flight may have been handed over before its progress was recorded
+			slotMap, err := operator.GetSlotMap(ctx, host, port)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get slot map: %w", err)
+			}
+			slots = slots.Intersect(slotMap.NodeSlots()[params.SourceID])
+		}
+
+		progress.Total = params.Slots.Count()
+		if err := operator.MigrateSlots(ctx, host, port, params.SourceID, params.TargetID, slots); err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"moved": progress.Done}, nil
+	case KindReshard:
+		params := ReshardParams{}
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, err
+		}
+
+		slots := params.Slots
+		unresolved := make([]cli.FixAction, 0)
+		if resumed {
+			var err error
+			unresolved, err = closeReshardSlots(ctx, operator, host, port, params)
+			if err != nil {
+				return nil, err
+			}
+			slots -= progress.Moved.Count()
+		}
+
+		if slots > 0 {
+			if err := operator.Reshard(ctx, host, port, params.TargetID, slots, params.SourceID); err != nil {
+				return nil, err
+			}
+		}
+
+		result := map[string]any{"moved": progress.Moved.Count()}
+		if len(unresolved) > 0 {
+			result["unresolved"] = unresolved
+		}
+		return result, nil
+	case KindRebalance:
+		params := RebalanceParams{}
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, err
+		}
+
+		// a rebalance plans against the current slot map, so running it again only moves what is left
+		nodes, err := operator.GetClusterNodes(ctx, host, port)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+		}
+
+		opts := cli.RebalanceOptions{
+			Weights:         params.Weights,
+			Threshold:       params.Threshold,
+			UseEmptyMasters: params.UseEmptyMasters,
+		}
+		if params.ByData {
+			usage, err := operator.MeasureSlots(ctx, host, port, params.Samples)
+			if err != nil {
+				return nil, fmt.Errorf("failed to measure slots: %w", err)
+			}
+			opts.SlotBytes = cli.SlotBytes(usage)
+		}
+
+		plan, err := cli.PlanRebalance(nodes, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		progress.Total = progress.Done + plan.SlotCount()
+		if err := operator.ExecuteRebalance(ctx, host, port, plan); err != nil {
+			return nil, err
+		}
+
+		return plan, nil
+	case KindScan:
+		params := ScanParams{}
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, err
+		}
+
+		// a scan keeps nothing between attempts, an interrupted one starts over
+		return operator.ScanKeyspace(ctx, host, port, params)
+	}
+
+	return nil, fmt.Errorf("%s: %w", job.Kind, ErrUnknownKind)
+}
+
+// closeReshardSlots finishes or rolls back the slots an interrupted valkey-cli left open between the source and the
+// target of the reshard. Everything else the cluster would need fixed is not the job's business, it is returned
+// for the result instead of being applied.
+func closeReshardSlots(ctx context.Context, operator cli.ClusterOperator, host string, port int, params ReshardParams) ([]cli.FixAction, error) {
+	actions, err := operator.FixCluster(ctx, host, port, cli.FixOptions{DryRun: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan fix of cluster: %w", err)
+	}
+
+	inFlight := cli.SlotSet{}
+	unresolved := make([]cli.FixAction, 0)
+	for _, action := range actions {
+		between := func(id string) bool { return action.Source == id || action.Target == id }
+		if action.Kind != cli.FixAssignSlots && between(params.TargetID) && (params.SourceID == "all" || between(params.SourceID)) {
+			inFlight = inFlight.Union(action.Slots)
+			continue
+		}
+		unresolved = append(unresolved, action)
+	}
+
+	for _, action := range unresolved {
+		log.Warn().Str("kind", string(action.Kind)).Str("slots", action.Slots.String()).Str("description", action.Description).
+			Msg("open slot left for a cluster fix")
+	}
+
+	if inFlight.IsEmpty() {
+		return unresolved, nil
+	}
+	if _, err := operator.FixCluster(ctx, host, port, cli.FixOptions{Slots: inFlight}); err != nil {
+		return nil, fmt.Errorf("failed to close slots %s: %w", inFlight, err)
+	}
+
+	return unresolved, nil
+}
+
+func (m *Manager) saveProgress(ctx context.Context, id int32, progress *Progress) {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		log.Error().Err(err).Int32("jobID", id).Msg("Failed to marshal job progress")
+		return
+	}
+
+	if err := m.jobs.saveProgress(ctx, id, data); err != nil {
+		log.Error().Err(err).Int32("jobID", id).Msg("Failed to save job progress")
+	}
+}
+
+func (m *Manager) finish(ctx context.Context, job *Job, result any, jobErr error) {
+	status := StatusSucceeded
+	switch {
+	case jobErr == nil:
+	case m.ctx.Err() != nil:
+		// the process is shutting down, keep the job running so Resume picks it up
+		log.Warn().Err(jobErr).Int32("jobID", job.ID).Msg("job interrupted")
+		return
+	case errors.Is(jobErr, context.Canceled) || errors.Is(jobErr, ErrJobCanceled):
+		status = StatusCanceled
+	default:
+		status = StatusFailed
+	}
+
+	var data []byte
+	if result != nil {
+		var err error
+		if data, err = json.Marshal(result); err != nil {
+			log.Error().Err(err).Int32("jobID", job.ID).Msg("Failed to marshal job result")
+		}
+	}
+
+	if err := m.jobs.finish(ctx, job.ID, status, data, jobErr); err != nil {
+		log.Error().Err(err).Int32("jobID", job.ID).Msg("Failed to finish job")
+		return
+	}
+
+	log.Info().Int32("jobID", job.ID).Str("status", string(status)).Err(jobErr).Msg("finish job")
+}
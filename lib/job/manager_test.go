@@ -0,0 +1,437 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/store/queries"
+)
+
+type fakeRepository struct {
+	lock   sync.Mutex
+	jobs   map[int32]*Job
+	nextID int32
+	seeds  []queries.Node
+	writes int
+}
+
+func newFakeRepository(seeds ...queries.Node) *fakeRepository {
+	return &fakeRepository{
+		jobs:  make(map[int32]*Job),
+		seeds: seeds,
+	}
+}
+
+func (r *fakeRepository) add(job *Job) *Job {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.nextID++
+	job.ID = r.nextID
+	r.jobs[job.ID] = job
+	return job
+}
+
+func (r *fakeRepository) job(id int32) Job {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return *r.jobs[id]
+}
+
+func (r *fakeRepository) create(ctx context.Context, clusterName string, kind Kind, params []byte) (*Job, error) {
+	job := r.add(&Job{Kind: kind, Status: StatusPending, Params: params})
+	copied := *job
+	return &copied, nil
+}
+
+func (r *fakeRepository) get(ctx context.Context, id int32) (*Job, error) {
+	job := r.job(id)
+	return &job, nil
+}
+
+func (r *fakeRepository) list(ctx context.Context, clusterName string, count int32) ([]*Job, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) unfinished(ctx context.Context) ([]*Job, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	jobs := make([]*Job, 0)
+	for _, status := range []Status{StatusRunning, StatusPending} {
+		for id := int32(1); id <= r.nextID; id++ {
+			if job := r.jobs[id]; job.Status == status {
+				copied := *job
+				jobs = append(jobs, &copied)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func (r *fakeRepository) start(ctx context.Context, job *Job) (queries.Cluster, []queries.Node, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.jobs[job.ID].Status = StatusRunning
+	return queries.Cluster{Name: "test"}, r.seeds, nil
+}
+
+func (r *fakeRepository) saveProgress(ctx context.Context, id int32, progress []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.writes++
+	r.jobs[id].Progress = &Progress{}
+	return json.Unmarshal(progress, r.jobs[id].Progress)
+}
+
+func (r *fakeRepository) finish(ctx context.Context, id int32, status Status, result []byte, jobErr error) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.jobs[id].Status = status
+	r.jobs[id].Result = result
+	if jobErr != nil {
+		r.jobs[id].Error = jobErr.Error()
+	}
+	return nil
+}
+
+// fakeOperator migrates slots by reporting them, migrate decides how a call against a seed ends.
+type fakeOperator struct {
+	cli.ClusterOperator
+
+	lock    sync.Mutex
+	owned   cli.SlotSet
+	calls   []string
+	slots   []cli.SlotSet
+	migrate func(ctx context.Context, call int) error
+
+	// actions is the plan of FixCluster, fixes and resharded record the calls changing the cluster
+	actions   []cli.FixAction
+	fixes     []cli.FixOptions
+	resharded []int
+}
+
+func (o *fakeOperator) FixCluster(ctx context.Context, host string, port int, opts cli.FixOptions) ([]cli.FixAction, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if !opts.DryRun {
+		o.fixes = append(o.fixes, opts)
+	}
+	return o.actions, nil
+}
+
+func (o *fakeOperator) Reshard(ctx context.Context, host string, port int, targetNode string, slots int, sourceNode string) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.resharded = append(o.resharded, slots)
+	return nil
+}
+
+func (o *fakeOperator) GetSlotMap(ctx context.Context, host string, port int) (*cli.SlotMap, error) {
+	return cli.NewSlotMap([]*cli.ClusterNode{
+		{ID: "source", Flags: cli.NewNodeFlags(cli.FlagMaster), Slots: o.owned},
+	}), nil
+}
+
+func (o *fakeOperator) MigrateSlots(ctx context.Context, host string, port int, sourceID string, targetID string, slots cli.SlotSet) error {
+	o.lock.Lock()
+	o.calls = append(o.calls, host)
+	o.slots = append(o.slots, slots)
+	call := len(o.calls)
+	o.lock.Unlock()
+
+	if o.migrate != nil {
+		if err := o.migrate(ctx, call); err != nil {
+			return err
+		}
+	}
+
+	for _, slot := range slots.Slots() {
+		cli.ReportProgress(ctx, cli.Progress{Operation: "migrate", Slot: slot, Source: sourceID, Target: targetID})
+	}
+	return nil
+}
+
+func migrateParams(t *testing.T, slots cli.SlotSet) json.RawMessage {
+	data, err := json.Marshal(MigrateParams{SourceID: "source", TargetID: "target", Slots: slots})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestProgressApply(t *testing.T) {
+	progress := &Progress{Moved: cli.SlotSet{{Start: 0, End: 1}}, Done: 2}
+
+	progress.apply(cli.Progress{Operation: "migrate", Slot: 2})
+	progress.apply(cli.Progress{Operation: "migrate", Slot: 5})
+	if progress.Done != 4 || progress.Moved.String() != "0-2,5" {
+		t.Errorf("after migrate = %+v, want 4 done of 0-2,5", progress)
+	}
+
+	progress.apply(cli.Progress{Operation: "reshard", Slot: 6, Done: 7, Total: 10})
+	if progress.Done != 7 || progress.Total != 10 || progress.Moved.String() != "0-2,5-6" {
+		t.Errorf("after reshard = %+v, want 7 of 10 done of 0-2,5-6", progress)
+	}
+
+	progress.apply(cli.Progress{Operation: "scan", Slot: -1, Done: 1, Total: 3})
+	if progress.Moved.String() != "0-2,5-6" || progress.Last.Operation != "scan" {
+		t.Errorf("after scan = %+v, want no slot moved", progress)
+	}
+}
+
+func TestManagerSubmit(t *testing.T) {
+	repo := newFakeRepository(queries.Node{Host: "10.0.0.1", Port: 7001})
+	operator := &fakeOperator{}
+	m := newManager(context.Background(), repo, func(string, string) cli.ClusterOperator { return operator })
+	m.progressInterval = time.Hour
+
+	events := 0
+	m.OnProgress(func(job *Job, progress cli.Progress) {
+		events++
+	})
+
+	created, err := m.Submit(context.Background(), "test", KindMigrate, MigrateParams{SourceID: "source", TargetID: "target", Slots: cli.SlotSet{{Start: 0, End: 99}}})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	m.Wait()
+
+	job := repo.job(created.ID)
+	if job.Status != StatusSucceeded {
+		t.Fatalf("Status = %s (%s), want succeeded", job.Status, job.Error)
+	}
+	if job.Progress.Done != 100 || job.Progress.Total != 100 || job.Progress.Moved.String() != "0-99" {
+		t.Errorf("Progress = %+v, want 0-99 moved", job.Progress)
+	}
+	if events != 100 {
+		t.Errorf("OnProgress got %d events, want 100", events)
+	}
+	// the first event and the final state, the interval keeps everything in between in memory
+	if repo.writes != 2 {
+		t.Errorf("progress written %d times, want 2", repo.writes)
+	}
+
+	if _, err := m.Submit(context.Background(), "test", KindMigrate, MigrateParams{SourceID: "source"}); err == nil {
+		t.Error("Submit() of invalid params succeeded")
+	}
+	if _, err := m.Submit(context.Background(), "test", Kind("unknown"), struct{}{}); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("Submit() of unknown kind = %v, want ErrUnknownKind", err)
+	}
+}
+
+func TestManagerRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status Status
+		calls  []string
+	}{
+		{
+			name:   "unreachable seed",
+			err:    &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			status: StatusSucceeded,
+			calls:  []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:   "connection failed",
+			err:    cli.ErrConnectionFailed,
+			status: StatusSucceeded,
+			calls:  []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:   "authentication",
+			err:    cli.ErrNoAuth,
+			status: StatusFailed,
+			calls:  []string{"10.0.0.1"},
+		},
+		{
+			name:   "migration failed partway",
+			err:    cli.RespError("IOERR error or timeout reading to target instance"),
+			status: StatusFailed,
+			calls:  []string{"10.0.0.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(queries.Node{Host: "10.0.0.1", Port: 7001}, queries.Node{Host: "10.0.0.2", Port: 7001})
+			operator := &fakeOperator{
+				owned: cli.SlotSet{{Start: 0, End: 9}},
+				migrate: func(ctx context.Context, call int) error {
+					if call == 1 {
+						return tt.err
+					}
+					return nil
+				},
+			}
+			m := newManager(context.Background(), repo, func(string, string) cli.ClusterOperator { return operator })
+
+			created, err := m.Submit(context.Background(), "test", KindMigrate, MigrateParams{SourceID: "source", TargetID: "target", Slots: cli.SlotSet{{Start: 0, End: 9}}})
+			if err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+			m.Wait()
+
+			if job := repo.job(created.ID); job.Status != tt.status {
+				t.Errorf("Status = %s (%s), want %s", job.Status, job.Error, tt.status)
+			}
+			if len(operator.calls) != len(tt.calls) {
+				t.Fatalf("MigrateSlots() called on %v, want %v", operator.calls, tt.calls)
+			}
+			for i, host := range tt.calls {
+				if operator.calls[i] != host {
+					t.Errorf("MigrateSlots() called on %v, want %v", operator.calls, tt.calls)
+				}
+			}
+		})
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	repo := newFakeRepository(queries.Node{Host: "10.0.0.1", Port: 7001}, queries.Node{Host: "10.0.0.2", Port: 7001})
+	started := make(chan struct{})
+	operator := &fakeOperator{
+		migrate: func(ctx context.Context, call int) error {
+			close(started)
+			<-ctx.Done()
+			return context.Cause(ctx)
+		},
+	}
+	m := newManager(context.Background(), repo, func(string, string) cli.ClusterOperator { return operator })
+
+	if err := m.Cancel(42); !errors.Is(err, ErrJobNotRunning) {
+		t.Errorf("Cancel() of unknown job = %v, want ErrJobNotRunning", err)
+	}
+
+	created, err := m.Submit(context.Background(), "test", KindMigrate, MigrateParams{SourceID: "source", TargetID: "target", Slots: cli.SlotSet{{Start: 0, End: 9}}})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-started
+
+	if err := m.Cancel(created.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	m.Wait()
+
+	if job := repo.job(created.ID); job.Status != StatusCanceled {
+		t.Errorf("Status = %s (%s), want canceled", job.Status, job.Error)
+	}
+	if len(operator.calls) != 1 {
+		t.Errorf("MigrateSlots() called %d times, want a canceled job not to move on to the next seed", len(operator.calls))
+	}
+	if err := m.Cancel(created.ID); !errors.Is(err, ErrJobNotRunning) {
+		t.Errorf("Cancel() of finished job = %v, want ErrJobNotRunning", err)
+	}
+}
+
+func TestManagerResume(t *testing.T) {
+	repo := newFakeRepository(queries.Node{Host: "10.0.0.1", Port: 7001})
+	// 5 and 6 were handed over before the process stopped, but only 0-4 were recorded
+	running := repo.add(&Job{
+		Kind:     KindMigrate,
+		Status:   StatusRunning,
+		Params:   migrateParams(t, cli.SlotSet{{Start: 0, End: 9}}),
+		Progress: &Progress{Done: 5, Moved: cli.SlotSet{{Start: 0, End: 4}}},
+	})
+	pending := repo.add(&Job{
+		Kind:   KindMigrate,
+		Status: StatusPending,
+		Params: migrateParams(t, cli.SlotSet{{Start: 100, End: 101}}),
+	})
+	finished := repo.add(&Job{
+		Kind:   KindMigrate,
+		Status: StatusSucceeded,
+		Params: migrateParams(t, cli.SlotSet{{Start: 200, End: 201}}),
+	})
+
+	operator := &fakeOperator{owned: cli.SlotSet{{Start: 7, End: 9}, {Start: 100, End: 101}}}
+	m := newManager(context.Background(), repo, func(string, string) cli.ClusterOperator { return operator })
+
+	if err := m.Resume(context.Background()); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	m.Wait()
+
+	for _, id := range []int32{running.ID, pending.ID} {
+		if job := repo.job(id); job.Status != StatusSucceeded {
+			t.Errorf("job %d Status = %s (%s), want succeeded", id, job.Status, job.Error)
+		}
+	}
+	if job := repo.job(finished.ID); job.Progress != nil {
+		t.Errorf("finished job was run again: %+v", job.Progress)
+	}
+
+	moved := make(map[string]bool)
+	for _, slots := range operator.slots {
+		moved[slots.String()] = true
+	}
+	if len(operator.slots) != 2 || !moved["7-9"] || !moved["100-101"] {
+		t.Errorf("MigrateSlots() moved %v, want 7-9 of the running job and 100-101 of the pending one", operator.slots)
+	}
+
+	job := repo.job(running.ID)
+	if job.Progress.Done != 8 || job.Progress.Total != 10 || job.Progress.Moved.String() != "0-4,7-9" {
+		t.Errorf("Progress = %+v, want 8 of 10 done", job.Progress)
+	}
+}
+
+func TestManagerResumeReshard(t *testing.T) {
+	repo := newFakeRepository(queries.Node{Host: "10.0.0.1", Port: 7001})
+	params, err := json.Marshal(ReshardParams{SourceID: "source", TargetID: "target", Slots: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	running := repo.add(&Job{
+		Kind:     KindReshard,
+		Status:   StatusRunning,
+		Params:   params,
+		Progress: &Progress{Moved: cli.SlotSet{{Start: 0, End: 3}}},
+	})
+
+	operator := &fakeOperator{actions: []cli.FixAction{
+		{Kind: cli.FixFinishMigration, Slots: cli.SlotSetOf(4), Source: "source", Target: "target"},
+		{Kind: cli.FixRollbackMigration, Slots: cli.SlotSetOf(9000), Source: "other", Target: "source"},
+		{Kind: cli.FixRollbackMigration, Slots: cli.SlotSetOf(5), Source: "target", Target: "source"},
+		{Kind: cli.FixAssignSlots, Slots: cli.SlotSet{{Start: 16000, End: 16383}}, Target: "target"},
+	}}
+	m := newManager(context.Background(), repo, func(string, string) cli.ClusterOperator { return operator })
+
+	if err := m.Resume(context.Background()); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	m.Wait()
+
+	job := repo.job(running.ID)
+	if job.Status != StatusSucceeded {
+		t.Fatalf("Status = %s (%s), want succeeded", job.Status, job.Error)
+	}
+	if len(operator.fixes) != 1 || operator.fixes[0].Slots.String() != "4-5" {
+		t.Errorf("FixCluster() applied %+v, want only the slots 4-5 in flight between source and target", operator.fixes)
+	}
+	if len(operator.resharded) != 1 || operator.resharded[0] != 6 {
+		t.Errorf("Reshard() moved %v, want the 6 slots left", operator.resharded)
+	}
+
+	result := struct {
+		Unresolved []cli.FixAction `json:"unresolved"`
+	}{}
+	if err := json.Unmarshal(job.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Unresolved) != 2 || result.Unresolved[0].Slots.String() != "9000" || result.Unresolved[1].Kind != cli.FixAssignSlots {
+		t.Errorf("unresolved = %+v, want the unrelated open slot and the uncovered slots", result.Unresolved)
+	}
+}
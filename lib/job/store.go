@@ -0,0 +1,176 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/snowmerak/keycl/lib/store"
+	"github.com/snowmerak/keycl/lib/store/queries"
+)
+
+// repository persists the jobs of a manager.
+type repository interface {
+	create(ctx context.Context, clusterName string, kind Kind, params []byte) (*Job, error)
+	get(ctx context.Context, id int32) (*Job, error)
+	list(ctx context.Context, clusterName string, count int32) ([]*Job, error)
+	// unfinished returns the running jobs followed by the pending ones.
+	unfinished(ctx context.Context) ([]*Job, error)
+	// start marks the job running and returns its cluster with the registered nodes.
+	start(ctx context.Context, job *Job) (queries.Cluster, []queries.Node, error)
+	saveProgress(ctx context.Context, id int32, progress []byte) error
+	finish(ctx context.Context, id int32, status Status, result []byte, jobErr error) error
+}
+
+type storeRepository struct {
+	store *store.Store
+}
+
+func (r storeRepository) create(ctx context.Context, clusterName string, kind Kind, params []byte) (*Job, error) {
+	var job *Job
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		row, err := q.CreateJob(ctx, queries.CreateJobParams{
+			Name:   clusterName,
+			Kind:   string(kind),
+			Params: params,
+		})
+		if err != nil {
+			return fmt.Errorf("q.CreateJob: %w", err)
+		}
+
+		job, err = fromRow(row)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (r storeRepository) get(ctx context.Context, id int32) (*Job, error) {
+	var job *Job
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		row, err := q.GetJob(ctx, id)
+		if err != nil {
+			return fmt.Errorf("q.GetJob: %w", err)
+		}
+
+		job, err = fromRow(row)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (r storeRepository) list(ctx context.Context, clusterName string, count int32) ([]*Job, error) {
+	jobs := make([]*Job, 0)
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		rows, err := q.GetClusterJobs(ctx, queries.GetClusterJobsParams{
+			Name:  clusterName,
+			Limit: count,
+		})
+		if err != nil {
+			return fmt.Errorf("q.GetClusterJobs: %w", err)
+		}
+
+		for _, row := range rows {
+			job, err := fromRow(row)
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, job)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r storeRepository) unfinished(ctx context.Context) ([]*Job, error) {
+	jobs := make([]*Job, 0)
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		for _, status := range []Status{StatusRunning, StatusPending} {
+			rows, err := q.GetJobsByStatus(ctx, string(status))
+			if err != nil {
+				return fmt.Errorf("q.GetJobsByStatus: %w", err)
+			}
+
+			for _, row := range rows {
+				job, err := fromRow(row)
+				if err != nil {
+					return err
+				}
+				jobs = append(jobs, job)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r storeRepository) start(ctx context.Context, job *Job) (queries.Cluster, []queries.Node, error) {
+	var cluster queries.Cluster
+	var seeds []queries.Node
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		var err error
+		cluster, err = q.GetClusterByID(ctx, job.ClusterID)
+		if err != nil {
+			return fmt.Errorf("q.GetClusterByID: %w", err)
+		}
+
+		seeds, err = q.GetClusterNodes(ctx, cluster.Name)
+		if err != nil {
+			return fmt.Errorf("q.GetClusterNodes: %w", err)
+		}
+		if len(seeds) == 0 {
+			return fmt.Errorf("cluster %s has no registered nodes", cluster.Name)
+		}
+
+		if _, err := q.StartJob(ctx, job.ID); err != nil {
+			return fmt.Errorf("q.StartJob: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return queries.Cluster{}, nil, err
+	}
+
+	return cluster, seeds, nil
+}
+
+func (r storeRepository) saveProgress(ctx context.Context, id int32, progress []byte) error {
+	return r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.UpdateJobProgress(ctx, queries.UpdateJobProgressParams{
+			Progress: progress,
+			ID:       id,
+		})
+		return err
+	})
+}
+
+func (r storeRepository) finish(ctx context.Context, id int32, status Status, result []byte, jobErr error) error {
+	errText := pgtype.Text{}
+	if jobErr != nil {
+		errText = pgtype.Text{String: jobErr.Error(), Valid: true}
+	}
+
+	return r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		_, err := q.FinishJob(ctx, queries.FinishJobParams{
+			Status: string(status),
+			Result: result,
+			Error:  errText,
+			ID:     id,
+		})
+		return err
+	})
+}
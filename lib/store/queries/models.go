@@ -17,6 +17,21 @@ type Cluster struct {
 	UpdatedAt   pgtype.Timestamp
 }
 
+type Job struct {
+	ID         int32
+	ClusterID  int32
+	Kind       string
+	Status     string
+	Params     []byte
+	Progress   []byte
+	Result     []byte
+	Error      pgtype.Text
+	CreatedAt  pgtype.Timestamp
+	UpdatedAt  pgtype.Timestamp
+	StartedAt  pgtype.Timestamp
+	FinishedAt pgtype.Timestamp
+}
+
 type Node struct {
 	ID          int32
 	ClusterID   int32
@@ -27,6 +42,10 @@ type Node struct {
 	IsCandidate bool
 	CreatedAt   pgtype.Timestamp
 	UpdatedAt   pgtype.Timestamp
+	Role        string
+	MasterID    string
+	Slots       string
+	SyncedAt    pgtype.Timestamp
 }
 
 type Password struct {
@@ -45,6 +64,14 @@ type Session struct {
 	ExpiresAt pgtype.Timestamp
 }
 
+type Snapshot struct {
+	ID        int32
+	ClusterID int32
+	Reason    string
+	Nodes     []byte
+	CreatedAt pgtype.Timestamp
+}
+
 type User struct {
 	ID        int32
 	Email     string
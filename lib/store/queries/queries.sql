@@ -77,13 +77,49 @@ SELECT * FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1)
 UPDATE nodes SET host = $1, port = $2, updated_at = now() WHERE node_id = $3 RETURNING *;
 
 -- name: ConnectNode :one
-UPDATE nodes SET connected = true, updated_at = now() WHERE node_id = $1 RETURNING *;
+UPDATE nodes SET connected = true, updated_at = now() WHERE id = $1 RETURNING *;
 
 -- name: DisconnectNode :one
-UPDATE nodes SET connected = false, updated_at = now() WHERE node_id = $1 RETURNING *;
+UPDATE nodes SET connected = false, updated_at = now() WHERE id = $1 RETURNING *;
 
 -- name: SetNodeCandidate :one
-UPDATE nodes SET is_candidate = $1, updated_at = now() WHERE node_id = $2 RETURNING *;
+UPDATE nodes SET is_candidate = $1, updated_at = now() WHERE id = $2 RETURNING *;
+
+-- name: SyncNode :one
+UPDATE nodes SET node_id = $1, host = $2, port = $3, role = $4, master_id = $5, slots = $6, synced_at = now(), updated_at = now() WHERE id = $7 RETURNING *;
 
 -- name: DeleteNode :one
-DELETE FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) AND node_id = $2 RETURNING *;
\ No newline at end of file
+DELETE FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) AND node_id = $2 RETURNING *;
+
+-- name: GetClusterByID :one
+SELECT * FROM clusters WHERE id = $1;
+
+-- name: CreateJob :one
+INSERT INTO jobs (cluster_id, kind, params) VALUES ((SELECT id FROM clusters WHERE name = $1), $2, $3) RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs WHERE id = $1;
+
+-- name: GetClusterJobs :many
+SELECT * FROM jobs WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) ORDER BY id DESC LIMIT $2;
+
+-- name: GetJobsByStatus :many
+SELECT * FROM jobs WHERE status = $1 ORDER BY id ASC;
+
+-- name: StartJob :one
+UPDATE jobs SET status = 'running', started_at = now(), updated_at = now() WHERE id = $1 RETURNING *;
+
+-- name: UpdateJobProgress :one
+UPDATE jobs SET progress = $1, updated_at = now() WHERE id = $2 RETURNING *;
+
+-- name: FinishJob :one
+UPDATE jobs SET status = $1, result = $2, error = $3, finished_at = now(), updated_at = now() WHERE id = $4 RETURNING *;
+
+-- name: CreateSnapshot :one
+INSERT INTO snapshots (cluster_id, reason, nodes) VALUES ((SELECT id FROM clusters WHERE name = $1), $2, $3) RETURNING *;
+
+-- name: GetSnapshot :one
+SELECT * FROM snapshots WHERE id = $1;
+
+-- name: GetClusterSnapshots :many
+SELECT * FROM snapshots WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) ORDER BY id DESC LIMIT $2;
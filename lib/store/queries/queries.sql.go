@@ -12,11 +12,11 @@ import (
 )
 
 const connectNode = `-- name: ConnectNode :one
-UPDATE nodes SET connected = true, updated_at = now() WHERE node_id = $1 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at
+UPDATE nodes SET connected = true, updated_at = now() WHERE id = $1 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at
 `
 
-func (q *Queries) ConnectNode(ctx context.Context, nodeID string) (Node, error) {
-	row := q.db.QueryRow(ctx, connectNode, nodeID)
+func (q *Queries) ConnectNode(ctx context.Context, id int32) (Node, error) {
+	row := q.db.QueryRow(ctx, connectNode, id)
 	var i Node
 	err := row.Scan(
 		&i.ID,
@@ -28,6 +28,10 @@ func (q *Queries) ConnectNode(ctx context.Context, nodeID string) (Node, error)
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
@@ -56,8 +60,38 @@ func (q *Queries) CreateCluster(ctx context.Context, arg CreateClusterParams) (C
 	return i, err
 }
 
+const createJob = `-- name: CreateJob :one
+INSERT INTO jobs (cluster_id, kind, params) VALUES ((SELECT id FROM clusters WHERE name = $1), $2, $3) RETURNING id, cluster_id, kind, status, params, progress, result, error, created_at, updated_at, started_at, finished_at
+`
+
+type CreateJobParams struct {
+	Name   string
+	Kind   string
+	Params []byte
+}
+
+func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
+	row := q.db.QueryRow(ctx, createJob, arg.Name, arg.Kind, arg.Params)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ClusterID,
+		&i.Kind,
+		&i.Status,
+		&i.Params,
+		&i.Progress,
+		&i.Result,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
 const createNode = `-- name: CreateNode :one
-INSERT INTO nodes (cluster_id, node_id, host, port) VALUES ((SELECT id FROM clusters WHERE name = $1), $2, $3, $4) RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at
+INSERT INTO nodes (cluster_id, node_id, host, port) VALUES ((SELECT id FROM clusters WHERE name = $1), $2, $3, $4) RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at
 `
 
 type CreateNodeParams struct {
@@ -85,6 +119,10 @@ func (q *Queries) CreateNode(ctx context.Context, arg CreateNodeParams) (Node, e
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
@@ -131,6 +169,29 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 	return i, err
 }
 
+const createSnapshot = `-- name: CreateSnapshot :one
+INSERT INTO snapshots (cluster_id, reason, nodes) VALUES ((SELECT id FROM clusters WHERE name = $1), $2, $3) RETURNING id, cluster_id, reason, nodes, created_at
+`
+
+type CreateSnapshotParams struct {
+	Name   string
+	Reason string
+	Nodes  []byte
+}
+
+func (q *Queries) CreateSnapshot(ctx context.Context, arg CreateSnapshotParams) (Snapshot, error) {
+	row := q.db.QueryRow(ctx, createSnapshot, arg.Name, arg.Reason, arg.Nodes)
+	var i Snapshot
+	err := row.Scan(
+		&i.ID,
+		&i.ClusterID,
+		&i.Reason,
+		&i.Nodes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (email) VALUES ($1) RETURNING id, email, is_admin, validated, deleted, created_at, updated_at
 `
@@ -169,7 +230,7 @@ func (q *Queries) DeleteCluster(ctx context.Context, name string) (Cluster, erro
 }
 
 const deleteNode = `-- name: DeleteNode :one
-DELETE FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) AND node_id = $2 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at
+DELETE FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) AND node_id = $2 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at
 `
 
 type DeleteNodeParams struct {
@@ -190,6 +251,10 @@ func (q *Queries) DeleteNode(ctx context.Context, arg DeleteNodeParams) (Node, e
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
@@ -214,11 +279,11 @@ func (q *Queries) DeleteUser(ctx context.Context, email string) (User, error) {
 }
 
 const disconnectNode = `-- name: DisconnectNode :one
-UPDATE nodes SET connected = false, updated_at = now() WHERE node_id = $1 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at
+UPDATE nodes SET connected = false, updated_at = now() WHERE id = $1 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at
 `
 
-func (q *Queries) DisconnectNode(ctx context.Context, nodeID string) (Node, error) {
-	row := q.db.QueryRow(ctx, disconnectNode, nodeID)
+func (q *Queries) DisconnectNode(ctx context.Context, id int32) (Node, error) {
+	row := q.db.QueryRow(ctx, disconnectNode, id)
 	var i Node
 	err := row.Scan(
 		&i.ID,
@@ -230,6 +295,10 @@ func (q *Queries) DisconnectNode(ctx context.Context, nodeID string) (Node, erro
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
@@ -253,6 +322,42 @@ func (q *Queries) ExpireSession(ctx context.Context, token string) (Session, err
 	return i, err
 }
 
+const finishJob = `-- name: FinishJob :one
+UPDATE jobs SET status = $1, result = $2, error = $3, finished_at = now(), updated_at = now() WHERE id = $4 RETURNING id, cluster_id, kind, status, params, progress, result, error, created_at, updated_at, started_at, finished_at
+`
+
+type FinishJobParams struct {
+	Status string
+	Result []byte
+	Error  pgtype.Text
+	ID     int32
+}
+
+func (q *Queries) FinishJob(ctx context.Context, arg FinishJobParams) (Job, error) {
+	row := q.db.QueryRow(ctx, finishJob,
+		arg.Status,
+		arg.Result,
+		arg.Error,
+		arg.ID,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ClusterID,
+		&i.Kind,
+		&i.Status,
+		&i.Params,
+		&i.Progress,
+		&i.Result,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
 const getCluster = `-- name: GetCluster :one
 SELECT id, name, description, password, created_at, updated_at FROM clusters WHERE name = $1
 `
@@ -271,8 +376,68 @@ func (q *Queries) GetCluster(ctx context.Context, name string) (Cluster, error)
 	return i, err
 }
 
+const getClusterByID = `-- name: GetClusterByID :one
+SELECT id, name, description, password, created_at, updated_at FROM clusters WHERE id = $1
+`
+
+func (q *Queries) GetClusterByID(ctx context.Context, id int32) (Cluster, error) {
+	row := q.db.QueryRow(ctx, getClusterByID, id)
+	var i Cluster
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getClusterJobs = `-- name: GetClusterJobs :many
+SELECT id, cluster_id, kind, status, params, progress, result, error, created_at, updated_at, started_at, finished_at FROM jobs WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) ORDER BY id DESC LIMIT $2
+`
+
+type GetClusterJobsParams struct {
+	Name  string
+	Limit int32
+}
+
+func (q *Queries) GetClusterJobs(ctx context.Context, arg GetClusterJobsParams) ([]Job, error) {
+	rows, err := q.db.Query(ctx, getClusterJobs, arg.Name, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClusterID,
+			&i.Kind,
+			&i.Status,
+			&i.Params,
+			&i.Progress,
+			&i.Result,
+			&i.Error,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getClusterNodes = `-- name: GetClusterNodes :many
-SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1)
+SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1)
 `
 
 func (q *Queries) GetClusterNodes(ctx context.Context, name string) ([]Node, error) {
@@ -294,6 +459,45 @@ func (q *Queries) GetClusterNodes(ctx context.Context, name string) ([]Node, err
 			&i.IsCandidate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Role,
+			&i.MasterID,
+			&i.Slots,
+			&i.SyncedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClusterSnapshots = `-- name: GetClusterSnapshots :many
+SELECT id, cluster_id, reason, nodes, created_at FROM snapshots WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) ORDER BY id DESC LIMIT $2
+`
+
+type GetClusterSnapshotsParams struct {
+	Name  string
+	Limit int32
+}
+
+func (q *Queries) GetClusterSnapshots(ctx context.Context, arg GetClusterSnapshotsParams) ([]Snapshot, error) {
+	rows, err := q.db.Query(ctx, getClusterSnapshots, arg.Name, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Snapshot
+	for rows.Next() {
+		var i Snapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClusterID,
+			&i.Reason,
+			&i.Nodes,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -372,8 +576,69 @@ func (q *Queries) GetClustersByCursor(ctx context.Context, arg GetClustersByCurs
 	return items, nil
 }
 
+const getJob = `-- name: GetJob :one
+SELECT id, cluster_id, kind, status, params, progress, result, error, created_at, updated_at, started_at, finished_at FROM jobs WHERE id = $1
+`
+
+func (q *Queries) GetJob(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, getJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ClusterID,
+		&i.Kind,
+		&i.Status,
+		&i.Params,
+		&i.Progress,
+		&i.Result,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getJobsByStatus = `-- name: GetJobsByStatus :many
+SELECT id, cluster_id, kind, status, params, progress, result, error, created_at, updated_at, started_at, finished_at FROM jobs WHERE status = $1 ORDER BY id ASC
+`
+
+func (q *Queries) GetJobsByStatus(ctx context.Context, status string) ([]Job, error) {
+	rows, err := q.db.Query(ctx, getJobsByStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClusterID,
+			&i.Kind,
+			&i.Status,
+			&i.Params,
+			&i.Progress,
+			&i.Result,
+			&i.Error,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getNode = `-- name: GetNode :one
-SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at FROM nodes WHERE node_id = $1
+SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at FROM nodes WHERE node_id = $1
 `
 
 func (q *Queries) GetNode(ctx context.Context, nodeID string) (Node, error) {
@@ -389,12 +654,16 @@ func (q *Queries) GetNode(ctx context.Context, nodeID string) (Node, error) {
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
 
 const getNodeByHostPort = `-- name: GetNodeByHostPort :one
-SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $3) AND host = $1 AND port = $2
+SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $3) AND host = $1 AND port = $2
 `
 
 type GetNodeByHostPortParams struct {
@@ -416,12 +685,16 @@ func (q *Queries) GetNodeByHostPort(ctx context.Context, arg GetNodeByHostPortPa
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
 
 const getNodeByNodeID = `-- name: GetNodeByNodeID :one
-SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $2) AND node_id = $1
+SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $2) AND node_id = $1
 `
 
 type GetNodeByNodeIDParams struct {
@@ -442,12 +715,16 @@ func (q *Queries) GetNodeByNodeID(ctx context.Context, arg GetNodeByNodeIDParams
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
 
 const getNodes = `-- name: GetNodes :many
-SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) ORDER BY node_id ASC LIMIT $2
+SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) ORDER BY node_id ASC LIMIT $2
 `
 
 type GetNodesParams struct {
@@ -474,6 +751,10 @@ func (q *Queries) GetNodes(ctx context.Context, arg GetNodesParams) ([]Node, err
 			&i.IsCandidate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Role,
+			&i.MasterID,
+			&i.Slots,
+			&i.SyncedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -486,7 +767,7 @@ func (q *Queries) GetNodes(ctx context.Context, arg GetNodesParams) ([]Node, err
 }
 
 const getNodesByCursor = `-- name: GetNodesByCursor :many
-SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) AND node_id > $2 ORDER BY node_id ASC LIMIT $3
+SELECT id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at FROM nodes WHERE cluster_id = (SELECT id FROM clusters WHERE name = $1) AND node_id > $2 ORDER BY node_id ASC LIMIT $3
 `
 
 type GetNodesByCursorParams struct {
@@ -514,6 +795,10 @@ func (q *Queries) GetNodesByCursor(ctx context.Context, arg GetNodesByCursorPara
 			&i.IsCandidate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Role,
+			&i.MasterID,
+			&i.Slots,
+			&i.SyncedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -544,6 +829,23 @@ func (q *Queries) GetSession(ctx context.Context, token string) (Session, error)
 	return i, err
 }
 
+const getSnapshot = `-- name: GetSnapshot :one
+SELECT id, cluster_id, reason, nodes, created_at FROM snapshots WHERE id = $1
+`
+
+func (q *Queries) GetSnapshot(ctx context.Context, id int32) (Snapshot, error) {
+	row := q.db.QueryRow(ctx, getSnapshot, id)
+	var i Snapshot
+	err := row.Scan(
+		&i.ID,
+		&i.ClusterID,
+		&i.Reason,
+		&i.Nodes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getUser = `-- name: GetUser :one
 SELECT id, email, is_admin, validated, deleted, created_at, updated_at FROM users WHERE email = $1
 `
@@ -618,16 +920,16 @@ func (q *Queries) GetUserPassword(ctx context.Context, email string) (GetUserPas
 }
 
 const setNodeCandidate = `-- name: SetNodeCandidate :one
-UPDATE nodes SET is_candidate = $1, updated_at = now() WHERE node_id = $2 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at
+UPDATE nodes SET is_candidate = $1, updated_at = now() WHERE id = $2 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at
 `
 
 type SetNodeCandidateParams struct {
 	IsCandidate bool
-	NodeID      string
+	ID          int32
 }
 
 func (q *Queries) SetNodeCandidate(ctx context.Context, arg SetNodeCandidateParams) (Node, error) {
-	row := q.db.QueryRow(ctx, setNodeCandidate, arg.IsCandidate, arg.NodeID)
+	row := q.db.QueryRow(ctx, setNodeCandidate, arg.IsCandidate, arg.ID)
 	var i Node
 	err := row.Scan(
 		&i.ID,
@@ -639,6 +941,77 @@ func (q *Queries) SetNodeCandidate(ctx context.Context, arg SetNodeCandidatePara
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
+	)
+	return i, err
+}
+
+const startJob = `-- name: StartJob :one
+UPDATE jobs SET status = 'running', started_at = now(), updated_at = now() WHERE id = $1 RETURNING id, cluster_id, kind, status, params, progress, result, error, created_at, updated_at, started_at, finished_at
+`
+
+func (q *Queries) StartJob(ctx context.Context, id int32) (Job, error) {
+	row := q.db.QueryRow(ctx, startJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ClusterID,
+		&i.Kind,
+		&i.Status,
+		&i.Params,
+		&i.Progress,
+		&i.Result,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const syncNode = `-- name: SyncNode :one
+UPDATE nodes SET node_id = $1, host = $2, port = $3, role = $4, master_id = $5, slots = $6, synced_at = now(), updated_at = now() WHERE id = $7 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at
+`
+
+type SyncNodeParams struct {
+	NodeID   string
+	Host     string
+	Port     int32
+	Role     string
+	MasterID string
+	Slots    string
+	ID       int32
+}
+
+func (q *Queries) SyncNode(ctx context.Context, arg SyncNodeParams) (Node, error) {
+	row := q.db.QueryRow(ctx, syncNode,
+		arg.NodeID,
+		arg.Host,
+		arg.Port,
+		arg.Role,
+		arg.MasterID,
+		arg.Slots,
+		arg.ID,
+	)
+	var i Node
+	err := row.Scan(
+		&i.ID,
+		&i.ClusterID,
+		&i.NodeID,
+		&i.Host,
+		&i.Port,
+		&i.Connected,
+		&i.IsCandidate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
@@ -673,8 +1046,37 @@ func (q *Queries) UpdateCluster(ctx context.Context, arg UpdateClusterParams) (C
 	return i, err
 }
 
+const updateJobProgress = `-- name: UpdateJobProgress :one
+UPDATE jobs SET progress = $1, updated_at = now() WHERE id = $2 RETURNING id, cluster_id, kind, status, params, progress, result, error, created_at, updated_at, started_at, finished_at
+`
+
+type UpdateJobProgressParams struct {
+	Progress []byte
+	ID       int32
+}
+
+func (q *Queries) UpdateJobProgress(ctx context.Context, arg UpdateJobProgressParams) (Job, error) {
+	row := q.db.QueryRow(ctx, updateJobProgress, arg.Progress, arg.ID)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ClusterID,
+		&i.Kind,
+		&i.Status,
+		&i.Params,
+		&i.Progress,
+		&i.Result,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
 const updateNode = `-- name: UpdateNode :one
-UPDATE nodes SET host = $1, port = $2, updated_at = now() WHERE node_id = $3 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at
+UPDATE nodes SET host = $1, port = $2, updated_at = now() WHERE node_id = $3 RETURNING id, cluster_id, node_id, host, port, connected, is_candidate, created_at, updated_at, role, master_id, slots, synced_at
 `
 
 type UpdateNodeParams struct {
@@ -696,6 +1098,10 @@ func (q *Queries) UpdateNode(ctx context.Context, arg UpdateNodeParams) (Node, e
 		&i.IsCandidate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.MasterID,
+		&i.Slots,
+		&i.SyncedAt,
 	)
 	return i, err
 }
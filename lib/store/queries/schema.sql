@@ -57,3 +57,38 @@ CREATE TABLE IF NOT EXISTS nodes
 CREATE INDEX IF NOT EXISTS nodes_cluster_id_index ON nodes (cluster_id);
 CREATE INDEX IF NOT EXISTS nodes_host_port_index ON nodes (cluster_id, host, port);
 CREATE INDEX IF NOT EXISTS nodes_node_id_index ON nodes (cluster_id, node_id);
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS master_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS slots TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS synced_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS jobs
+(
+    id SERIAL PRIMARY KEY,
+    cluster_id INTEGER NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    kind VARCHAR(64) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    params JSONB NOT NULL,
+    progress JSONB,
+    result JSONB,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS jobs_cluster_id_index ON jobs (cluster_id);
+CREATE INDEX IF NOT EXISTS jobs_status_index ON jobs (status);
+
+CREATE TABLE IF NOT EXISTS snapshots
+(
+    id SERIAL PRIMARY KEY,
+    cluster_id INTEGER NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
+    reason VARCHAR(255) NOT NULL,
+    nodes JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS snapshots_cluster_id_index ON snapshots (cluster_id, id);
@@ -3,40 +3,39 @@ package store
 import (
 	"context"
 	"fmt"
-	"sync"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/snowmerak/keycl/lib/store/queries"
 )
 
+// Store hands out connections of a pool, so requests, jobs and the syncer do not wait behind each other.
 type Store struct {
-	conn *pgx.Conn
-	lock sync.Mutex
+	pool *pgxpool.Pool
 }
 
 func New(ctx context.Context, connectionString string) (*Store, error) {
-	conn, err := pgx.Connect(ctx, connectionString)
+	pool, err := pgxpool.New(ctx, connectionString)
 	if err != nil {
-		return nil, fmt.Errorf("pgx.Connect: %w", err)
+		return nil, fmt.Errorf("pgxpool.New: %w", err)
 	}
 
 	context.AfterFunc(ctx, func() {
-		conn.Close(ctx)
+		pool.Close()
 	})
 
-	return &Store{conn: conn}, nil
+	return &Store{pool: pool}, nil
 }
 
 func (s *Store) Visit(ctx context.Context, visitor func(ctx context.Context, q *queries.Queries) error) error {
-	q := queries.New(s.conn)
+	q := queries.New(s.pool)
 	return visitor(ctx, q)
 }
 
 func (s *Store) VisitTx(ctx context.Context, visitor func(ctx context.Context, q *queries.Queries) error) error {
-	tx, err := s.conn.Begin(ctx)
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("conn.Begin: %w", err)
+		return fmt.Errorf("pool.Begin: %w", err)
 	}
 
 	q := queries.New(tx)
@@ -51,5 +50,5 @@ func (s *Store) VisitTx(ctx context.Context, visitor func(ctx context.Context, q
 		return fmt.Errorf("tx.Commit: %w", err)
 	}
 
-	return visitor(ctx, q)
+	return nil
 }
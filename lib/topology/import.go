@@ -0,0 +1,100 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/snowmerak/keycl/lib/store/queries"
+)
+
+var (
+	ErrClusterExists  = errors.New("cluster already exists")
+	ErrNodeRegistered = errors.New("node belongs to another cluster")
+)
+
+type ImportOptions struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Password    string `json:"password"`
+	// Host and Port are the seed the topology is read from.
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+// ImportCluster registers a running cluster from a single seed: the cluster and every node it reports are stored in
+// one transaction, so nothing is registered when any node already belongs to another cluster.
+func (s *Syncer) ImportCluster(ctx context.Context, opts ImportOptions) ([]queries.Node, error) {
+	nodes, err := s.newOperator(opts.Name, opts.Password).GetClusterNodes(ctx, opts.Host, opts.Port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	syncs, _ := planSync(nil, nodes, opts.Host)
+
+	rows := make([]queries.Node, 0, len(syncs))
+	if err := s.store.VisitTx(ctx, func(ctx context.Context, q *queries.Queries) error {
+		if _, err := q.GetCluster(ctx, opts.Name); err == nil {
+			return fmt.Errorf("cluster %s: %w", opts.Name, ErrClusterExists)
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("q.GetCluster: %w", err)
+		}
+
+		for _, sync := range syncs {
+			existing, err := q.GetNode(ctx, sync.params.NodeID)
+			if err == nil {
+				other, err := q.GetClusterByID(ctx, existing.ClusterID)
+				if err != nil {
+					return fmt.Errorf("q.GetClusterByID: %w", err)
+				}
+				return fmt.Errorf("node %s of cluster %s: %w", existing.NodeID, other.Name, ErrNodeRegistered)
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("q.GetNode: %w", err)
+			}
+		}
+
+		if _, err := q.CreateCluster(ctx, queries.CreateClusterParams{
+			Name:        opts.Name,
+			Description: pgtype.Text{String: opts.Description, Valid: opts.Description != ""},
+			Password:    opts.Password,
+		}); err != nil {
+			return fmt.Errorf("q.CreateCluster: %w", err)
+		}
+
+		for _, sync := range syncs {
+			created, err := q.CreateNode(ctx, queries.CreateNodeParams{
+				Name:   opts.Name,
+				NodeID: sync.params.NodeID,
+				Host:   sync.params.Host,
+				Port:   sync.params.Port,
+			})
+			if err != nil {
+				return fmt.Errorf("q.CreateNode: %w", err)
+			}
+
+			sync.params.ID = created.ID
+			row, err := q.SyncNode(ctx, sync.params)
+			if err != nil {
+				return fmt.Errorf("q.SyncNode: %w", err)
+			}
+
+			if sync.connected {
+				row, err = q.ConnectNode(ctx, row.ID)
+				if err != nil {
+					return fmt.Errorf("q.ConnectNode: %w", err)
+				}
+			}
+			rows = append(rows, row)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,182 @@
+package topology
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/snowmerak/keycl/lib/cli"
+)
+
+// recordingOperator snapshots the cluster before and after every operation changing it. Failing to record a
+// snapshot is logged and never fails the operation itself.
+type recordingOperator struct {
+	cli.ClusterOperator
+	recorder    *Recorder
+	clusterName string
+}
+
+func (o *recordingOperator) record(ctx context.Context, reason string, host string, port int) {
+	// a canceled operation is exactly the one worth a snapshot afterwards
+	ctx = context.WithoutCancel(ctx)
+	if _, err := o.recorder.Capture(ctx, o.clusterName, reason, o.ClusterOperator, host, port); err != nil {
+		log.Warn().Err(err).Str("cluster", o.clusterName).Str("reason", reason).Msg("Failed to record topology snapshot")
+	}
+}
+
+func (o *recordingOperator) around(ctx context.Context, operation string, host string, port int, fn func() error) error {
+	o.record(ctx, "before "+operation, host, port)
+	err := fn()
+	o.record(ctx, "after "+operation, host, port)
+	return err
+}
+
+func (o *recordingOperator) CreateCluster(ctx context.Context, replicas int, address ...string) error {
+	if len(address) == 0 {
+		return o.ClusterOperator.CreateCluster(ctx, replicas, address...)
+	}
+
+	host, portText, err := net.SplitHostPort(address[0])
+	if err != nil {
+		return o.ClusterOperator.CreateCluster(ctx, replicas, address...)
+	}
+	port, _ := strconv.Atoi(portText)
+
+	// there is no cluster to record before it is created
+	err = o.ClusterOperator.CreateCluster(ctx, replicas, address...)
+	o.record(ctx, "after create cluster", host, port)
+	return err
+}
+
+func (o *recordingOperator) AddNode(ctx context.Context, newNodeHost string, newNodePort int, existingNodeHost string, existingNodePort int) error {
+	return o.around(ctx, "add node", existingNodeHost, existingNodePort, func() error {
+		return o.ClusterOperator.AddNode(ctx, newNodeHost, newNodePort, existingNodeHost, existingNodePort)
+	})
+}
+
+func (o *recordingOperator) Reshard(ctx context.Context, host string, port int, targetNode string, slots int, sourceNode string) error {
+	return o.around(ctx, "reshard", host, port, func() error {
+		return o.ClusterOperator.Reshard(ctx, host, port, targetNode, slots, sourceNode)
+	})
+}
+
+func (o *recordingOperator) ReshardAll(ctx context.Context, host string, port int) error {
+	return o.around(ctx, "reshard all", host, port, func() error {
+		return o.ClusterOperator.ReshardAll(ctx, host, port)
+	})
+}
+
+func (o *recordingOperator) ForgetNode(ctx context.Context, host string, port int, nodeID string) error {
+	return o.around(ctx, "forget node", host, port, func() error {
+		return o.ClusterOperator.ForgetNode(ctx, host, port, nodeID)
+	})
+}
+
+func (o *recordingOperator) DeleteNode(ctx context.Context, host string, port int, nodeID string) error {
+	return o.around(ctx, "delete node", host, port, func() error {
+		return o.ClusterOperator.DeleteNode(ctx, host, port, nodeID)
+	})
+}
+
+func (o *recordingOperator) ReplicateNode(ctx context.Context, host string, port int, masterNodeID string) error {
+	return o.around(ctx, "replicate node", host, port, func() error {
+		return o.ClusterOperator.ReplicateNode(ctx, host, port, masterNodeID)
+	})
+}
+
+func (o *recordingOperator) Failover(ctx context.Context, host string, port int, opts cli.FailoverOptions) error {
+	return o.around(ctx, "failover", host, port, func() error {
+		return o.ClusterOperator.Failover(ctx, host, port, opts)
+	})
+}
+
+func (o *recordingOperator) ApplyReplicaPlan(ctx context.Context, plan *cli.ReplicaPlan) error {
+	if len(plan.Assignments) == 0 {
+		return o.ClusterOperator.ApplyReplicaPlan(ctx, plan)
+	}
+
+	first := plan.Assignments[0]
+	return o.around(ctx, "apply replica plan", first.ReplicaHost, first.ReplicaPort, func() error {
+		return o.ClusterOperator.ApplyReplicaPlan(ctx, plan)
+	})
+}
+
+func (o *recordingOperator) Rebalance(ctx context.Context, host string, port int) error {
+	return o.around(ctx, "rebalance", host, port, func() error {
+		return o.ClusterOperator.Rebalance(ctx, host, port)
+	})
+}
+
+func (o *recordingOperator) RebalanceWith(ctx context.Context, host string, port int, opts cli.RebalanceOptions) (*cli.RebalancePlan, error) {
+	var plan *cli.RebalancePlan
+	err := o.around(ctx, "rebalance", host, port, func() error {
+		var err error
+		plan, err = o.ClusterOperator.RebalanceWith(ctx, host, port, opts)
+		return err
+	})
+	return plan, err
+}
+
+func (o *recordingOperator) ExecuteRebalance(ctx context.Context, host string, port int, plan *cli.RebalancePlan) error {
+	return o.around(ctx, "rebalance", host, port, func() error {
+		return o.ClusterOperator.ExecuteRebalance(ctx, host, port, plan)
+	})
+}
+
+func (o *recordingOperator) DecommissionNode(ctx context.Context, host string, port int, nodeID string, opts cli.DecommissionOptions) (*cli.DecommissionResult, error) {
+	var result *cli.DecommissionResult
+	err := o.around(ctx, "decommission node", host, port, func() error {
+		var err error
+		result, err = o.ClusterOperator.DecommissionNode(ctx, host, port, nodeID, opts)
+		return err
+	})
+	return result, err
+}
+
+func (o *recordingOperator) ExceptNode(ctx context.Context, host string, port int, exceptionNode string) error {
+	return o.around(ctx, "except node", host, port, func() error {
+		return o.ClusterOperator.ExceptNode(ctx, host, port, exceptionNode)
+	})
+}
+
+func (o *recordingOperator) MergeNode(ctx context.Context, host string, port int, targetNodeID string, sourceNodeID string) error {
+	return o.around(ctx, "merge node", host, port, func() error {
+		return o.ClusterOperator.MergeNode(ctx, host, port, targetNodeID, sourceNodeID)
+	})
+}
+
+func (o *recordingOperator) MigrateSlots(ctx context.Context, host string, port int, sourceID string, targetID string, slots cli.SlotSet) error {
+	return o.around(ctx, "migrate slots", host, port, func() error {
+		return o.ClusterOperator.MigrateSlots(ctx, host, port, sourceID, targetID, slots)
+	})
+}
+
+func (o *recordingOperator) FixCluster(ctx context.Context, host string, port int, opts cli.FixOptions) ([]cli.FixAction, error) {
+	if opts.DryRun {
+		return o.ClusterOperator.FixCluster(ctx, host, port, opts)
+	}
+
+	var actions []cli.FixAction
+	err := o.around(ctx, "fix cluster", host, port, func() error {
+		var err error
+		actions, err = o.ClusterOperator.FixCluster(ctx, host, port, opts)
+		return err
+	})
+	return actions, err
+}
+
+func (o *recordingOperator) Reconcile(ctx context.Context, host string, port int, spec *cli.ClusterSpec, opts cli.ReconcileOptions) (*cli.ReconcilePlan, error) {
+	if opts.DryRun {
+		return o.ClusterOperator.Reconcile(ctx, host, port, spec, opts)
+	}
+
+	var plan *cli.ReconcilePlan
+	err := o.around(ctx, "reconcile", host, port, func() error {
+		var err error
+		plan, err = o.ClusterOperator.Reconcile(ctx, host, port, spec, opts)
+		return err
+	})
+	return plan, err
+}
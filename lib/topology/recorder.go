@@ -0,0 +1,233 @@
+package topology
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/store"
+	"github.com/snowmerak/keycl/lib/store/queries"
+)
+
+const ReasonScheduled = "scheduled"
+
+// Recorder persists topology snapshots of the clusters to the snapshots table.
+type Recorder struct {
+	store *store.Store
+}
+
+func NewRecorder(store *store.Store) *Recorder {
+	return &Recorder{
+		store: store,
+	}
+}
+
+func fromRow(row queries.Snapshot) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		ID:        row.ID,
+		ClusterID: row.ClusterID,
+		Reason:    row.Reason,
+		CreatedAt: row.CreatedAt.Time,
+	}
+
+	if err := json.Unmarshal(row.Nodes, &snapshot.Nodes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal nodes of snapshot %d: %w", row.ID, err)
+	}
+
+	return snapshot, nil
+}
+
+// Capture reads the topology of the cluster through host:port and stores it with the reason.
+func (r *Recorder) Capture(ctx context.Context, clusterName string, reason string, operator cli.ClusterOperator, host string, port int) (*Snapshot, error) {
+	nodes, err := operator.GetClusterNodes(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	return r.save(ctx, clusterName, reason, FromNodes(nodes, host))
+}
+
+func (r *Recorder) save(ctx context.Context, clusterName string, reason string, nodes []Node) (*Snapshot, error) {
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal nodes: %w", err)
+	}
+
+	var snapshot *Snapshot
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		row, err := q.CreateSnapshot(ctx, queries.CreateSnapshotParams{
+			Name:   clusterName,
+			Reason: reason,
+			Nodes:  data,
+		})
+		if err != nil {
+			return fmt.Errorf("q.CreateSnapshot: %w", err)
+		}
+
+		snapshot, err = fromRow(row)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (r *Recorder) Get(ctx context.Context, id int32) (*Snapshot, error) {
+	var snapshot *Snapshot
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		row, err := q.GetSnapshot(ctx, id)
+		if err != nil {
+			return fmt.Errorf("q.GetSnapshot: %w", err)
+		}
+
+		snapshot, err = fromRow(row)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// List returns the latest count snapshots of the cluster, newest first.
+func (r *Recorder) List(ctx context.Context, clusterName string, count int32) ([]*Snapshot, error) {
+	snapshots := make([]*Snapshot, 0)
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		rows, err := q.GetClusterSnapshots(ctx, queries.GetClusterSnapshotsParams{
+			Name:  clusterName,
+			Limit: count,
+		})
+		if err != nil {
+			return fmt.Errorf("q.GetClusterSnapshots: %w", err)
+		}
+
+		for _, row := range rows {
+			snapshot, err := fromRow(row)
+			if err != nil {
+				return err
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// Factory wraps newOperator so every operator it builds records a snapshot before and after each operation changing the cluster.
+func (r *Recorder) Factory(newOperator cli.OperatorFactory) cli.OperatorFactory {
+	return func(clusterName string, password string) cli.ClusterOperator {
+		return &recordingOperator{
+			ClusterOperator: newOperator(clusterName, password),
+			recorder:        r,
+			clusterName:     clusterName,
+		}
+	}
+}
+
+// Schedule captures every cluster each interval until ctx is done. A snapshot is only stored when the topology
+// differs from the latest snapshot of the cluster.
+func (r *Recorder) Schedule(ctx context.Context, newOperator cli.OperatorFactory, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := r.captureAll(ctx, newOperator); err != nil {
+				log.Error().Err(err).Msg("Failed to capture scheduled snapshots")
+			}
+		}
+	}()
+}
+
+func (r *Recorder) captureAll(ctx context.Context, newOperator cli.OperatorFactory) error {
+	clusters := make([]queries.Cluster, 0)
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		cursor := ""
+		for {
+			rows, err := q.GetClustersByCursor(ctx, queries.GetClustersByCursorParams{
+				Name:  cursor,
+				Limit: 100,
+			})
+			if err != nil {
+				return fmt.Errorf("q.GetClustersByCursor: %w", err)
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+
+			clusters = append(clusters, rows...)
+			cursor = rows[len(rows)-1].Name
+		}
+	}); err != nil {
+		return err
+	}
+
+	for _, cluster := range clusters {
+		if err := r.captureChanged(ctx, cluster, newOperator(cluster.Name, cluster.Password)); err != nil {
+			log.Warn().Err(err).Str("cluster", cluster.Name).Msg("Failed to capture scheduled snapshot")
+		}
+	}
+
+	return nil
+}
+
+func (r *Recorder) captureChanged(ctx context.Context, cluster queries.Cluster, operator cli.ClusterOperator) error {
+	var seeds []queries.Node
+	var latest *Snapshot
+	if err := r.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		var err error
+		seeds, err = q.GetClusterNodes(ctx, cluster.Name)
+		if err != nil {
+			return fmt.Errorf("q.GetClusterNodes: %w", err)
+		}
+
+		rows, err := q.GetClusterSnapshots(ctx, queries.GetClusterSnapshotsParams{
+			Name:  cluster.Name,
+			Limit: 1,
+		})
+		if err != nil {
+			return fmt.Errorf("q.GetClusterSnapshots: %w", err)
+		}
+		if len(rows) > 0 {
+			latest, err = fromRow(rows[0])
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+
+	errs := make([]error, 0, len(seeds))
+	for _, seed := range seeds {
+		nodes, err := operator.GetClusterNodes(ctx, seed.Host, int(seed.Port))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %w", seed.Host, seed.Port, err))
+			continue
+		}
+
+		current := &Snapshot{Nodes: FromNodes(nodes, seed.Host)}
+		if latest != nil && len(Diff(latest, current)) == 0 {
+			return nil
+		}
+
+		_, err = r.save(ctx, cluster.Name, ReasonScheduled, current.Nodes)
+		return err
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/store/queries"
+)
+
+var ErrUnknownAddress = errors.New("address is not a node of the cluster")
+
+// ResolveNode looks host:port up in the topology reported by the registered nodes of a cluster. Operations on a node
+// named by a request connect to the address returned, so the password of the cluster is only ever sent to the
+// registered nodes and to the nodes they know, never to an arbitrary address.
+func ResolveNode(ctx context.Context, operator cli.ClusterOperator, seeds []queries.Node, host string, port int) (*cli.ClusterNode, error) {
+	errs := make([]error, 0, len(seeds))
+	for _, seed := range seeds {
+		nodes, err := operator.GetClusterNodes(ctx, seed.Host, int(seed.Port))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %w", seed.Host, seed.Port, err))
+			if cli.IsUnreachable(err) {
+				continue
+			}
+			return nil, errors.Join(errs...)
+		}
+
+		node := findNode(nodes, host, port, seed.Host)
+		if node == nil {
+			return nil, fmt.Errorf("%s:%d: %w", host, port, ErrUnknownAddress)
+		}
+		return node, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("cluster has no registered nodes")
+	}
+	return nil, fmt.Errorf("failed to get cluster nodes: %w", errors.Join(errs...))
+}
+
+// findNode returns the node listening on host:port, a node reporting no address of its own gets the one of the seed.
+func findNode(nodes []*cli.ClusterNode, host string, port int, seedHost string) *cli.ClusterNode {
+	for _, node := range nodes {
+		if node.Flags.Has(cli.FlagNoAddr) || node.Flags.Has(cli.FlagHandshake) || node.Port != port {
+			continue
+		}
+
+		nodeHost := node.Host
+		if nodeHost == "" {
+			nodeHost = seedHost
+		}
+		if nodeHost != host && (node.Hostname == "" || node.Hostname != host) {
+			continue
+		}
+
+		found := *node
+		found.Host = host
+		return &found
+	}
+
+	return nil
+}
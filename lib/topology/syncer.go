@@ -0,0 +1,288 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/store"
+	"github.com/snowmerak/keycl/lib/store/queries"
+)
+
+// Syncer keeps the nodes table in line with the live topology of the clusters. Nodes found in a cluster without a
+// row are stored as candidates, so they show up without taking part in anything until they are registered.
+type Syncer struct {
+	store       *store.Store
+	newOperator cli.OperatorFactory
+}
+
+func NewSyncer(store *store.Store, newOperator cli.OperatorFactory) *Syncer {
+	return &Syncer{
+		store:       store,
+		newOperator: newOperator,
+	}
+}
+
+type SyncResult struct {
+	Added        []string `json:"added"`
+	Updated      []string `json:"updated"`
+	Connected    []string `json:"connected"`
+	Disconnected []string `json:"disconnected"`
+}
+
+// nodeSync is the state a row of the nodes table should be brought to, row is nil for a node without one.
+type nodeSync struct {
+	row       *queries.Node
+	params    queries.SyncNodeParams
+	connected bool
+}
+
+func (s nodeSync) changed() bool {
+	r := s.row
+	p := s.params
+	return r.NodeID != p.NodeID || r.Host != p.Host || r.Port != p.Port || r.Role != p.Role ||
+		r.MasterID != p.MasterID || r.Slots != p.Slots || !r.SyncedAt.Valid
+}
+
+// planSync pairs the live nodes with the rows of the cluster, by node ID first and by address for rows stored before
+// their ID was known. Rows left without a live node are returned as missing.
+func planSync(rows []queries.Node, nodes []*cli.ClusterNode, seedHost string) ([]nodeSync, []queries.Node) {
+	paired := make([]bool, len(rows))
+	find := func(match func(row queries.Node) bool) *queries.Node {
+		for i := range rows {
+			if !paired[i] && match(rows[i]) {
+				paired[i] = true
+				return &rows[i]
+			}
+		}
+		return nil
+	}
+
+	syncs := make([]nodeSync, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Flags.Has(cli.FlagNoAddr) {
+			continue
+		}
+
+		host := node.Host
+		if host == "" {
+			host = seedHost
+		}
+
+		params := queries.SyncNodeParams{
+			NodeID: node.ID,
+			Host:   host,
+			Port:   int32(node.Port),
+			Role:   string(cli.RoleMaster),
+			Slots:  node.Slots.String(),
+		}
+		if node.IsReplica() {
+			params.Role = string(cli.RoleReplica)
+			params.MasterID = node.MasterID
+		}
+
+		row := find(func(row queries.Node) bool { return row.NodeID == node.ID })
+		if row == nil {
+			row = find(func(row queries.Node) bool { return row.Host == host && row.Port == params.Port })
+		}
+		if row != nil {
+			params.ID = row.ID
+		}
+
+		syncs = append(syncs, nodeSync{
+			row:       row,
+			params:    params,
+			connected: node.IsConnected() && !node.IsFailing(),
+		})
+	}
+
+	missing := make([]queries.Node, 0)
+	for i, row := range rows {
+		if !paired[i] {
+			missing = append(missing, row)
+		}
+	}
+
+	return syncs, missing
+}
+
+// SyncCluster reads the topology of the cluster through the first stored node answering and writes it to the nodes table
+// in one transaction.
+func (s *Syncer) SyncCluster(ctx context.Context, clusterName string) (*SyncResult, error) {
+	var cluster queries.Cluster
+	var rows []queries.Node
+	if err := s.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		var err error
+		cluster, err = q.GetCluster(ctx, clusterName)
+		if err != nil {
+			return fmt.Errorf("q.GetCluster: %w", err)
+		}
+
+		rows, err = q.GetClusterNodes(ctx, clusterName)
+		if err != nil {
+			return fmt.Errorf("q.GetClusterNodes: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	operator := s.newOperator(cluster.Name, cluster.Password)
+
+	var nodes []*cli.ClusterNode
+	seedHost := ""
+	errs := make([]error, 0, len(rows))
+	for _, row := range rows {
+		var err error
+		nodes, err = operator.GetClusterNodes(ctx, row.Host, int(row.Port))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %w", row.Host, row.Port, err))
+			continue
+		}
+		seedHost = row.Host
+		break
+	}
+	if nodes == nil {
+		if len(errs) == 0 {
+			return nil, fmt.Errorf("cluster %s has no nodes to sync from", clusterName)
+		}
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", errors.Join(errs...))
+	}
+
+	syncs, missing := planSync(rows, nodes, seedHost)
+
+	result := &SyncResult{
+		Added:        make([]string, 0),
+		Updated:      make([]string, 0),
+		Connected:    make([]string, 0),
+		Disconnected: make([]string, 0),
+	}
+	if err := s.store.VisitTx(ctx, func(ctx context.Context, q *queries.Queries) error {
+		for _, sync := range syncs {
+			row := sync.row
+			if row == nil {
+				created, err := q.CreateNode(ctx, queries.CreateNodeParams{
+					Name:   cluster.Name,
+					NodeID: sync.params.NodeID,
+					Host:   sync.params.Host,
+					Port:   sync.params.Port,
+				})
+				if err != nil {
+					return fmt.Errorf("q.CreateNode: %w", err)
+				}
+				if _, err := q.SetNodeCandidate(ctx, queries.SetNodeCandidateParams{
+					IsCandidate: true,
+					ID:          created.ID,
+				}); err != nil {
+					return fmt.Errorf("q.SetNodeCandidate: %w", err)
+				}
+
+				sync.params.ID = created.ID
+				row = &created
+				result.Added = append(result.Added, sync.params.NodeID)
+			}
+
+			if sync.row == nil || sync.changed() {
+				if _, err := q.SyncNode(ctx, sync.params); err != nil {
+					return fmt.Errorf("q.SyncNode: %w", err)
+				}
+				if sync.row != nil {
+					result.Updated = append(result.Updated, sync.params.NodeID)
+				}
+			}
+
+			switch {
+			case sync.connected && !row.Connected:
+				if _, err := q.ConnectNode(ctx, row.ID); err != nil {
+					return fmt.Errorf("q.ConnectNode: %w", err)
+				}
+				result.Connected = append(result.Connected, sync.params.NodeID)
+			case !sync.connected && row.Connected:
+				if _, err := q.DisconnectNode(ctx, row.ID); err != nil {
+					return fmt.Errorf("q.DisconnectNode: %w", err)
+				}
+				result.Disconnected = append(result.Disconnected, sync.params.NodeID)
+			}
+		}
+
+		for _, row := range missing {
+			if !row.Connected {
+				continue
+			}
+			if _, err := q.DisconnectNode(ctx, row.ID); err != nil {
+				return fmt.Errorf("q.DisconnectNode: %w", err)
+			}
+			result.Disconnected = append(result.Disconnected, row.NodeID)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Run syncs every cluster each interval until ctx is done.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := s.syncAll(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to sync clusters")
+			}
+		}
+	}()
+}
+
+func (s *Syncer) syncAll(ctx context.Context) error {
+	names := make([]string, 0)
+	if err := s.store.Visit(ctx, func(ctx context.Context, q *queries.Queries) error {
+		cursor := ""
+		for {
+			rows, err := q.GetClustersByCursor(ctx, queries.GetClustersByCursorParams{
+				Name:  cursor,
+				Limit: 100,
+			})
+			if err != nil {
+				return fmt.Errorf("q.GetClustersByCursor: %w", err)
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+
+			for _, row := range rows {
+				names = append(names, row.Name)
+			}
+			cursor = rows[len(rows)-1].Name
+		}
+	}); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		result, err := s.SyncCluster(ctx, name)
+		if err != nil {
+			log.Warn().Err(err).Str("cluster", name).Msg("Failed to sync cluster")
+			continue
+		}
+		if len(result.Added)+len(result.Updated)+len(result.Connected)+len(result.Disconnected) > 0 {
+			log.Info().Str("cluster", name).Strs("added", result.Added).Strs("updated", result.Updated).
+				Strs("connected", result.Connected).Strs("disconnected", result.Disconnected).Msg("synced cluster")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package topology
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snowmerak/keycl/lib/cli"
+)
+
+type Node struct {
+	ID          string       `json:"id"`
+	Address     string       `json:"address"`
+	Role        cli.NodeRole `json:"role"`
+	MasterID    string       `json:"master_id,omitempty"`
+	Failing     bool         `json:"failing,omitempty"`
+	ConfigEpoch int64        `json:"config_epoch"`
+	Slots       cli.SlotSet  `json:"slots,omitempty"`
+}
+
+// Snapshot is the topology of a cluster at one point in time.
+type Snapshot struct {
+	ID        int32     `json:"id"`
+	ClusterID int32     `json:"cluster_id"`
+	Reason    string    `json:"reason"`
+	Nodes     []Node    `json:"nodes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FromNodes turns the output of GetClusterNodes into the nodes of a snapshot, the node answering for itself
+// without an address gets seedHost.
+func FromNodes(nodes []*cli.ClusterNode, seedHost string) []Node {
+	result := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		host := n.Host
+		if host == "" && !n.Flags.Has(cli.FlagNoAddr) {
+			host = seedHost
+		}
+
+		node := Node{
+			ID:          n.ID,
+			Address:     host + ":" + strconv.Itoa(n.Port),
+			Role:        cli.RoleMaster,
+			Failing:     n.IsFailing(),
+			ConfigEpoch: n.ConfigEpoch,
+			Slots:       n.Slots,
+		}
+		if n.IsReplica() {
+			node.Role = cli.RoleReplica
+			node.MasterID = n.MasterID
+		}
+		result = append(result, node)
+	}
+
+	slices.SortFunc(result, func(a, b Node) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return result
+}
+
+type ChangeKind string
+
+const (
+	ChangeNodeAdded      ChangeKind = "node_added"
+	ChangeNodeRemoved    ChangeKind = "node_removed"
+	ChangeAddressChanged ChangeKind = "address_changed"
+	ChangeRoleChanged    ChangeKind = "role_changed"
+	ChangeMasterChanged  ChangeKind = "master_changed"
+	ChangeNodeFailing    ChangeKind = "node_failing"
+	ChangeNodeRecovered  ChangeKind = "node_recovered"
+	ChangeSlotsMoved     ChangeKind = "slots_moved"
+)
+
+type Change struct {
+	Kind   ChangeKind `json:"kind"`
+	NodeID string     `json:"node_id,omitempty"`
+	// From and To are the old and new value of the change, e.g. the role or, for slots, the owning masters.
+	From    string      `json:"from,omitempty"`
+	To      string      `json:"to,omitempty"`
+	Slots   cli.SlotSet `json:"slots,omitempty"`
+	Message string      `json:"message"`
+}
+
+// Diff explains what changed from one snapshot to the other: nodes added and removed, changes of address, role,
+// master and health, and the slots that changed their owner.
+func Diff(from, to *Snapshot) []Change {
+	changes := make([]Change, 0)
+
+	before := make(map[string]Node, len(from.Nodes))
+	for _, n := range from.Nodes {
+		before[n.ID] = n
+	}
+	after := make(map[string]Node, len(to.Nodes))
+	for _, n := range to.Nodes {
+		after[n.ID] = n
+	}
+
+	ids := make([]string, 0, len(before)+len(after))
+	for id := range before {
+		ids = append(ids, id)
+	}
+	for id := range after {
+		if _, ok := before[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		old, existed := before[id]
+		cur, exists := after[id]
+
+		switch {
+		case !existed:
+			changes = append(changes, Change{
+				Kind:    ChangeNodeAdded,
+				NodeID:  id,
+				To:      cur.Address,
+				Message: fmt.Sprintf("%s %s joined at %s", cur.Role, id, cur.Address),
+			})
+			continue
+		case !exists:
+			changes = append(changes, Change{
+				Kind:    ChangeNodeRemoved,
+				NodeID:  id,
+				From:    old.Address,
+				Message: fmt.Sprintf("%s %s at %s left", old.Role, id, old.Address),
+			})
+			continue
+		}
+
+		if old.Address != cur.Address {
+			changes = append(changes, Change{
+				Kind:    ChangeAddressChanged,
+				NodeID:  id,
+				From:    old.Address,
+				To:      cur.Address,
+				Message: fmt.Sprintf("%s moved from %s to %s", id, old.Address, cur.Address),
+			})
+		}
+
+		switch {
+		case old.Role != cur.Role:
+			message := fmt.Sprintf("%s became %s", id, cur.Role)
+			if cur.Role == cli.RoleReplica {
+				message += " of " + cur.MasterID
+			}
+			changes = append(changes, Change{
+				Kind:    ChangeRoleChanged,
+				NodeID:  id,
+				From:    string(old.Role),
+				To:      string(cur.Role),
+				Message: message,
+			})
+		case old.MasterID != cur.MasterID:
+			changes = append(changes, Change{
+				Kind:    ChangeMasterChanged,
+				NodeID:  id,
+				From:    old.MasterID,
+				To:      cur.MasterID,
+				Message: fmt.Sprintf("replica %s follows %s instead of %s", id, cur.MasterID, old.MasterID),
+			})
+		}
+
+		switch {
+		case !old.Failing && cur.Failing:
+			changes = append(changes, Change{Kind: ChangeNodeFailing, NodeID: id, Message: id + " is failing"})
+		case old.Failing && !cur.Failing:
+			changes = append(changes, Change{Kind: ChangeNodeRecovered, NodeID: id, Message: id + " recovered"})
+		}
+	}
+
+	return append(changes, diffSlots(from.Nodes, to.Nodes)...)
+}
+
+func slotOwners(nodes []Node) []string {
+	owners := make([]string, cli.MaxSlotCount)
+	for _, n := range nodes {
+		if n.Role != cli.RoleMaster {
+			continue
+		}
+		for _, slot := range n.Slots.Slots() {
+			owners[slot] = n.ID
+		}
+	}
+	return owners
+}
+
+// diffSlots groups the slots whose owner changed by their old and new owner, an empty owner is an unassigned slot.
+func diffSlots(from, to []Node) []Change {
+	before, after := slotOwners(from), slotOwners(to)
+
+	moved := make(map[[2]string][]int)
+	for slot := range before {
+		if before[slot] != after[slot] {
+			key := [2]string{before[slot], after[slot]}
+			moved[key] = append(moved[key], slot)
+		}
+	}
+
+	keys := make([][2]string, 0, len(moved))
+	for key := range moved {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b [2]string) int {
+		if c := strings.Compare(a[0], b[0]); c != 0 {
+			return c
+		}
+		return strings.Compare(a[1], b[1])
+	})
+
+	changes := make([]Change, 0, len(keys))
+	for _, key := range keys {
+		slots := cli.SlotSetOf(moved[key]...)
+
+		message := ""
+		switch {
+		case key[0] == "":
+			message = fmt.Sprintf("slots %s assigned to %s", slots, key[1])
+		case key[1] == "":
+			message = fmt.Sprintf("slots %s of %s unassigned", slots, key[0])
+		default:
+			message = fmt.Sprintf("slots %s moved from %s to %s", slots, key[0], key[1])
+		}
+
+		changes = append(changes, Change{
+			Kind:    ChangeSlotsMoved,
+			From:    key[0],
+			To:      key[1],
+			Slots:   slots,
+			Message: message,
+		})
+	}
+
+	return changes
+}
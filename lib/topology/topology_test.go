@@ -0,0 +1,155 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/snowmerak/keycl/lib/cli"
+	"github.com/snowmerak/keycl/lib/store/queries"
+)
+
+func TestFromNodes(t *testing.T) {
+	nodes := []*cli.ClusterNode{
+		{ID: "b", Port: 7002, Host: "10.0.0.2", Flags: cli.NewNodeFlags(cli.FlagSlave), MasterID: "a"},
+		{ID: "a", Port: 7001, Flags: cli.NewNodeFlags(cli.FlagMyself, cli.FlagMaster), ConfigEpoch: 3, Slots: cli.SlotSet{{Start: 0, End: 16383}}},
+	}
+
+	got := FromNodes(nodes, "10.0.0.1")
+	if len(got) != 2 || got[0].ID != "a" || got[0].Address != "10.0.0.1:7001" || got[0].ConfigEpoch != 3 {
+		t.Fatalf("FromNodes() = %+v", got)
+	}
+	if got[1].Role != cli.RoleReplica || got[1].MasterID != "a" {
+		t.Errorf("FromNodes()[1] = %+v, want replica of a", got[1])
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := &Snapshot{Nodes: []Node{
+		{ID: "a", Address: "10.0.0.1:7001", Role: cli.RoleMaster, Slots: cli.SlotSet{{Start: 0, End: 8191}}},
+		{ID: "b", Address: "10.0.0.2:7001", Role: cli.RoleMaster, Slots: cli.SlotSet{{Start: 8192, End: 16383}}},
+		{ID: "c", Address: "10.0.0.3:7001", Role: cli.RoleReplica, MasterID: "a"},
+		{ID: "d", Address: "10.0.0.4:7001", Role: cli.RoleReplica, MasterID: "b"},
+	}}
+	to := &Snapshot{Nodes: []Node{
+		{ID: "a", Address: "10.0.0.1:7001", Role: cli.RoleReplica, MasterID: "c", Failing: true},
+		{ID: "b", Address: "10.0.0.2:7001", Role: cli.RoleMaster, Slots: cli.SlotSet{{Start: 8192, End: 16383}, {Start: 0, End: 99}}},
+		{ID: "c", Address: "10.0.0.3:7001", Role: cli.RoleMaster, Slots: cli.SlotSet{{Start: 100, End: 8191}}},
+		{ID: "e", Address: "10.0.0.5:7001", Role: cli.RoleReplica, MasterID: "b"},
+	}}
+
+	changes := Diff(from, to)
+
+	want := []struct {
+		kind   ChangeKind
+		nodeID string
+		from   string
+		to     string
+		slots  string
+	}{
+		{ChangeRoleChanged, "a", "master", "replica", ""},
+		{ChangeNodeFailing, "a", "", "", ""},
+		{ChangeRoleChanged, "c", "replica", "master", ""},
+		{ChangeNodeRemoved, "d", "10.0.0.4:7001", "", ""},
+		{ChangeNodeAdded, "e", "", "10.0.0.5:7001", ""},
+		{ChangeSlotsMoved, "", "a", "b", "0-99"},
+		{ChangeSlotsMoved, "", "a", "c", "100-8191"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Diff() = %+v, want %d changes", changes, len(want))
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Kind != w.kind || c.NodeID != w.nodeID || c.From != w.from || c.To != w.to || c.Slots.String() != w.slots {
+			t.Errorf("changes[%d] = %+v, want %+v", i, c, w)
+		}
+		if c.Message == "" {
+			t.Errorf("changes[%d] has no message", i)
+		}
+	}
+
+	if changes := Diff(to, to); len(changes) != 0 {
+		t.Errorf("Diff() of the same snapshot = %+v, want none", changes)
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	rows := []queries.Node{
+		{ID: 1, NodeID: "a", Host: "10.0.0.1", Port: 7001, Connected: true},
+		{ID: 2, NodeID: "manual", Host: "10.0.0.2", Port: 7001},
+		{ID: 3, NodeID: "gone", Host: "10.0.0.9", Port: 7001, Connected: true},
+	}
+	nodes := []*cli.ClusterNode{
+		{ID: "a", Port: 7001, Flags: cli.NewNodeFlags(cli.FlagMyself, cli.FlagMaster), LinkState: "connected", Slots: cli.SlotSet{{Start: 0, End: 16383}}},
+		{ID: "b", Host: "10.0.0.2", Port: 7001, Flags: cli.NewNodeFlags(cli.FlagSlave), MasterID: "a", LinkState: "connected"},
+		{ID: "c", Host: "10.0.0.3", Port: 7001, Flags: cli.NewNodeFlags(cli.FlagSlave, cli.FlagFail), MasterID: "a", LinkState: "disconnected"},
+		{ID: "d", Flags: cli.NewNodeFlags(cli.FlagNoAddr, cli.FlagMaster)},
+	}
+
+	syncs, missing := planSync(rows, nodes, "10.0.0.1")
+	if len(syncs) != 3 {
+		t.Fatalf("planSync() = %+v, want 3 nodes", syncs)
+	}
+
+	want := []struct {
+		rowID     int32
+		host      string
+		role      string
+		slots     string
+		connected bool
+	}{
+		{1, "10.0.0.1", "master", "0-16383", true},
+		{2, "10.0.0.2", "replica", "", true},
+		{0, "10.0.0.3", "replica", "", false},
+	}
+	for i, w := range want {
+		s := syncs[i]
+		if s.params.ID != w.rowID || s.params.Host != w.host || s.params.Role != w.role || s.params.Slots != w.slots || s.connected != w.connected {
+			t.Errorf("syncs[%d] = %+v, want %+v", i, s.params, w)
+		}
+		if (s.row == nil) != (w.rowID == 0) {
+			t.Errorf("syncs[%d] paired with %+v", i, s.row)
+		}
+	}
+	if syncs[1].params.NodeID != "b" || !syncs[1].changed() {
+		t.Errorf("syncs[1] = %+v, want the stored node to take the ID b", syncs[1].params)
+	}
+
+	if len(missing) != 1 || missing[0].NodeID != "gone" {
+		t.Errorf("missing = %+v, want gone", missing)
+	}
+}
+
+func TestFindNode(t *testing.T) {
+	nodes := []*cli.ClusterNode{
+		{ID: "a", Port: 7001, Flags: cli.NewNodeFlags(cli.FlagMyself, cli.FlagMaster)},
+		{ID: "b", Host: "10.0.0.2", Port: 7002, Hostname: "node-2", Flags: cli.NewNodeFlags(cli.FlagSlave), MasterID: "a"},
+		{ID: "c", Host: "10.0.0.3", Port: 7003, Flags: cli.NewNodeFlags(cli.FlagMaster, cli.FlagHandshake)},
+		{ID: "d", Port: 7004, Flags: cli.NewNodeFlags(cli.FlagMaster, cli.FlagNoAddr)},
+	}
+
+	tests := []struct {
+		name string
+		host string
+		port int
+		want string
+	}{
+		{"seed without an address of its own", "10.0.0.1", 7001, "a"},
+		{"gossiped address", "10.0.0.2", 7002, "b"},
+		{"gossiped hostname", "node-2", 7002, "b"},
+		{"other port", "10.0.0.2", 7001, ""},
+		{"node in handshake", "10.0.0.3", 7003, ""},
+		{"node without address", "10.0.0.1", 7004, ""},
+		{"unknown address", "192.0.2.1", 7001, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findNode(nodes, tt.host, tt.port, "10.0.0.1")
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("findNode() = %s, want none", got.ID)
+			case tt.want != "" && (got == nil || got.ID != tt.want || got.Host != tt.host):
+				t.Errorf("findNode() = %+v, want %s at %s", got, tt.want, tt.host)
+			}
+		})
+	}
+}
@@ -15,20 +15,16 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	c := cli.New(cli.Valkey, "")
+	c := cli.New(cli.Valkey, "", cli.WithWait(30*time.Second))
 
 	if err := c.CreateCluster(ctx, 0, "127.0.0.1:7001", "127.0.0.1:7002", "127.0.0.1:7003"); err != nil {
 		panic(err)
 	}
 
-	time.Sleep(3 * time.Second)
-
 	if err := c.AddNode(ctx, "127.0.0.1", 7005, "127.0.0.1", 7002); err != nil {
 		panic(err)
 	}
 
-	time.Sleep(3 * time.Second)
-
 	if err := c.ReshardAll(ctx, "127.0.0.1", 7001); err != nil {
 		panic(err)
 	}